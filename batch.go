@@ -0,0 +1,259 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+)
+
+// maxBatchLine is the longest input line GreetLines accepts.
+const maxBatchLine = 1 << 20
+
+// reorderWindow is how many jobs per worker may be in flight ahead of the
+// oldest unfinished one. It bounds the memory used to restore order.
+const reorderWindow = 4
+
+// lineChunk is how many lines GreetLines hands to a worker at once, which
+// amortizes the channel operations over many cheap greetings.
+const lineChunk = 256
+
+// GreetFunc greets a single name. SayHiStrict and (*LocaleGreeter).SayHiStrict
+// both satisfy it.
+type GreetFunc func(name string) (string, error)
+
+// BatchResult is the outcome for one record of a batch.
+type BatchResult struct {
+	Index    int    // position of the record in the input; the 0-based line number for GreetLines
+	Name     string // the name as read from the input
+	Greeting string // the greeting, if Err is nil
+	Err      error  // why the record could not be greeted
+}
+
+// BatchStats summarizes a GreetLines run.
+type BatchStats struct {
+	Total  int // records processed
+	Failed int // records that produced an error
+}
+
+// BatchGreeter greets large streams of names on a bounded pool of worker
+// goroutines while preserving input order in its output. A failing record
+// is reported on its own and does not stop the batch.
+//
+// Example:
+//
+//	b := NewBatchGreeter(nil, 8)
+//	stats, err := b.GreetLines(ctx, os.Stdin, os.Stdout, func(r BatchResult) {
+//		log.Printf("line %d: %v", r.Index+1, r.Err)
+//	})
+//
+// Thread Safety:
+//   A BatchGreeter may run several batches concurrently.
+type BatchGreeter struct {
+	greet   GreetFunc
+	workers int
+}
+
+// NewBatchGreeter returns a BatchGreeter that greets each record with greet
+// on the given number of workers. A nil greet means SayHiStrict; workers
+// below 1 means runtime.GOMAXPROCS(0).
+func NewBatchGreeter(greet GreetFunc, workers int) *BatchGreeter {
+	if greet == nil {
+		greet = SayHiStrict
+	}
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return &BatchGreeter{greet: greet, workers: workers}
+}
+
+// batchJob is a run of records greeted by one worker.
+type batchJob struct {
+	indexes []int
+	names   []string
+	slot    chan []BatchResult
+}
+
+// Greet greets every name received from names and delivers the results in
+// the order the names arrived. The returned channel is closed once names is
+// closed and all results are delivered, or as soon as ctx is done; in the
+// latter case results still in flight are dropped.
+func (b *BatchGreeter) Greet(ctx context.Context, names <-chan string) <-chan BatchResult {
+	jobs := make(chan batchJob)
+	go func() {
+		defer close(jobs)
+		for i := 0; ; i++ {
+			select {
+			case name, ok := <-names:
+				if !ok {
+					return
+				}
+				select {
+				case jobs <- batchJob{indexes: []int{i}, names: []string{name}}:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return b.process(ctx, jobs)
+}
+
+// GreetLines reads one name per line from r and writes one greeting per
+// line to w, in input order. Blank lines are skipped. Records that fail are
+// passed to onError, if it is not nil, and left out of the output.
+//
+// GreetLines returns early with an error if reading r or writing w fails or
+// ctx is done.
+func (b *BatchGreeter) GreetLines(ctx context.Context, r io.Reader, w io.Writer, onError func(BatchResult)) (BatchStats, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan batchJob)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(jobs)
+		var job batchJob
+		send := func() bool {
+			select {
+			case jobs <- job:
+				job = batchJob{}
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, maxBatchLine)
+		for i := 0; scanner.Scan(); i++ {
+			name := scanner.Text()
+			if strings.TrimSpace(name) == "" {
+				continue
+			}
+			job.indexes = append(job.indexes, i)
+			job.names = append(job.names, name)
+			if len(job.names) == lineChunk && !send() {
+				return
+			}
+		}
+		if len(job.names) > 0 && !send() {
+			return
+		}
+		readErr <- scanner.Err()
+	}()
+
+	var stats BatchStats
+	bw := bufio.NewWriter(w)
+	for res := range b.process(ctx, jobs) {
+		stats.Total++
+		if res.Err != nil {
+			stats.Failed++
+			if onError != nil {
+				onError(res)
+			}
+			continue
+		}
+		bw.WriteString(res.Greeting)
+		if err := bw.WriteByte('\n'); err != nil {
+			return stats, err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return stats, err
+	}
+	if err := <-readErr; err != nil {
+		return stats, err
+	}
+	return stats, bw.Flush()
+}
+
+// process runs jobs through the worker pool. Each job gets a result slot
+// that is queued, in input order, on pending; the collector waits on the
+// slots one by one, so results leave in order however the workers finish.
+func (b *BatchGreeter) process(ctx context.Context, jobs <-chan batchJob) <-chan BatchResult {
+	work := make(chan batchJob)
+	pending := make(chan batchJob, b.workers*reorderWindow)
+	out := make(chan BatchResult)
+
+	go func() {
+		defer close(work)
+		defer close(pending)
+		for {
+			var j batchJob
+			var ok bool
+			select {
+			case j, ok = <-jobs:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+
+			j.slot = make(chan []BatchResult, 1)
+			select {
+			case pending <- j:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case work <- j:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < b.workers; i++ {
+		go func() {
+			for j := range work {
+				results := make([]BatchResult, len(j.names))
+				for k, name := range j.names {
+					results[k] = b.greetOne(j.indexes[k], name)
+				}
+				j.slot <- results
+			}
+		}()
+	}
+
+	go func() {
+		defer close(out)
+		for j := range pending {
+			var results []BatchResult
+			select {
+			case results = <-j.slot:
+			case <-ctx.Done():
+				return
+			}
+			for _, res := range results {
+				select {
+				case out <- res:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// greetOne greets a single record, turning a panic in the GreetFunc into
+// an error for that record.
+func (b *BatchGreeter) greetOne(index int, name string) (res BatchResult) {
+	res = BatchResult{Index: index, Name: name}
+	defer func() {
+		if p := recover(); p != nil {
+			res.Greeting = ""
+			res.Err = fmt.Errorf("greeting %q panicked: %v", name, p)
+		}
+	}()
+	res.Greeting, res.Err = b.greet(name)
+	return res
+}
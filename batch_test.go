@@ -0,0 +1,129 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestBatchGreeterOrder tests that results keep input order with uneven work
+func TestBatchGreeterOrder(t *testing.T) {
+	slow := func(name string) (string, error) {
+		if len(name)%3 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		return SayHi(name), nil
+	}
+
+	names := make(chan string)
+	go func() {
+		defer close(names)
+		for i := 0; i < 500; i++ {
+			names <- fmt.Sprint("name", i)
+		}
+	}()
+
+	i := 0
+	for res := range NewBatchGreeter(slow, 8).Greet(context.Background(), names) {
+		want := fmt.Sprint("name", i)
+		if res.Index != i || res.Name != want || res.Greeting != SayHi(want) || res.Err != nil {
+			t.Fatalf("result %d = %+v, want greeting for %q", i, res, want)
+		}
+		i++
+	}
+	if i != 500 {
+		t.Errorf("got %d results, want 500", i)
+	}
+}
+
+// TestBatchGreeterGreetLines tests line processing with per-record errors
+func TestBatchGreeterGreetLines(t *testing.T) {
+	input := "Alice\n\n  Bob  \nEve\x00\nCharlie\n"
+	var out bytes.Buffer
+	var failed []BatchResult
+
+	stats, err := NewBatchGreeter(nil, 4).GreetLines(context.Background(), strings.NewReader(input), &out, func(r BatchResult) {
+		failed = append(failed, r)
+	})
+	if err != nil {
+		t.Fatalf("GreetLines error: %v", err)
+	}
+
+	if want := "Hi, Alice\nHi, Bob\nHi, Charlie\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+	if stats != (BatchStats{Total: 4, Failed: 1}) {
+		t.Errorf("stats = %+v, want {Total:4 Failed:1}", stats)
+	}
+	if len(failed) != 1 || failed[0].Index != 3 || !errors.Is(failed[0].Err, ErrControlChars) {
+		t.Errorf("failed records = %+v, want line 4 with ErrControlChars", failed)
+	}
+}
+
+// TestBatchGreeterPanic tests that a panicking GreetFunc fails only its record
+func TestBatchGreeterPanic(t *testing.T) {
+	greet := func(name string) (string, error) {
+		if name == "boom" {
+			panic("exploded")
+		}
+		return SayHi(name), nil
+	}
+
+	var out bytes.Buffer
+	stats, err := NewBatchGreeter(greet, 2).GreetLines(context.Background(), strings.NewReader("a\nboom\nb\n"), &out, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "Hi, a\nHi, b\n" || stats.Failed != 1 {
+		t.Errorf("output = %q, stats = %+v", out.String(), stats)
+	}
+}
+
+// TestBatchGreeterCancel tests that cancellation stops the batch
+func TestBatchGreeterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	names := make(chan string) // never closed
+
+	results := NewBatchGreeter(nil, 2).Greet(ctx, names)
+	names <- "Alice"
+	if res := <-results; res.Greeting != "Hi, Alice" {
+		t.Fatalf("first result = %+v", res)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-results:
+		if ok {
+			t.Error("received a result after cancellation")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("results channel not closed after cancellation")
+	}
+}
+
+// TestBatchGreeterWriteError tests that output errors abort the batch
+func TestBatchGreeterWriteError(t *testing.T) {
+	errDisk := errors.New("disk full")
+	input := strings.Repeat("Alice\n", 10000)
+
+	_, err := NewBatchGreeter(nil, 4).GreetLines(context.Background(), strings.NewReader(input), &failingWriter{err: errDisk}, nil)
+	if !errors.Is(err, errDisk) {
+		t.Errorf("GreetLines error = %v, want %v", err, errDisk)
+	}
+}
+
+// BenchmarkBatchGreeterGreetLines benchmarks line throughput
+func BenchmarkBatchGreeterGreetLines(b *testing.B) {
+	input := strings.Repeat("José Müller\n", 1000)
+	batch := NewBatchGreeter(nil, 0)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := batch.GreetLines(context.Background(), strings.NewReader(input), &bytes.Buffer{}, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -6,6 +6,24 @@ set -e
 
 echo "Building optimized versions..."
 
+# Test both SayHi implementations; the differential tests check they agree
+echo "Testing default and reference implementations..."
+go vet ./...
+go test ./...
+go test -tags greet_reference .
+
+# The gRPC service needs Go 1.25+ for gRPC, so it is a module of its own
+(cd greetgrpc && go vet ./... && go test ./...)
+
+# Run the SQLite profile store against a real engine; the driver lives in
+# its own module so the core module does not depend on it
+(cd sqlitetest && go test ./...)
+
+# Brotli and zstd for greethttp.Compress live in their own module too
+(cd greetcompress && go vet ./... && go test ./...)
+
+# The examples are //go:build ignore programs, built by file name.
+
 # Build flags for optimization
 BUILD_FLAGS="-ldflags=-s -w"
 RACE_FLAGS="-race"
@@ -23,6 +41,13 @@ go build -race -o bin/greeting-server-race examples/web_server_optimized.go
 echo "Building optimized CLI..."
 go build -ldflags="-s -w" -o bin/greeting-cli-optimized examples/cli_app.go
 
+# Build the greet command with version information
+echo "Building greet command..."
+VERSION=$(git describe --tags --always --dirty 2>/dev/null || echo dev)
+COMMIT=$(git rev-parse HEAD 2>/dev/null || true)
+DATE=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+go build -ldflags="-s -w -X main.version=$VERSION -X main.commit=$COMMIT -X main.date=$DATE" -o bin/greet ./cmd/greet
+
 # Show binary sizes
 echo ""
 echo "Binary sizes:"
@@ -31,9 +56,17 @@ ls -lh bin/
 # Run benchmarks
 echo ""
 echo "Running benchmarks..."
-go test -bench=. -benchmem ./say_test.go ./say_optimized.go
+go test -run='^$' -bench=SayHi -benchmem .
+
+# Compare pooled rendering with SayHiBytes across GOMAXPROCS values, and
+# under the race detector, which drops pooled buffers at random
+echo ""
+echo "Running pooled rendering benchmarks..."
+go test -run='^$' -bench=Render -benchmem -cpu 1,2,4,8 .
+go test -race -run='^$' -bench=Render -benchmem -benchtime=100000x -cpu 1,8 .
 
 echo ""
 echo "Build complete!"
 echo "Optimized server: bin/greeting-server-optimized"
-echo "CLI tool: bin/greeting-cli-optimized"
\ No newline at end of file
+echo "CLI tool: bin/greeting-cli-optimized"
+echo "greet command: bin/greet"
\ No newline at end of file
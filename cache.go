@@ -0,0 +1,260 @@
+package test
+
+import (
+	"container/list"
+	"hash/maphash"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// cacheEntryOverhead approximates the memory an entry costs beyond its
+// name and greeting: the list element, map slot and bookkeeping.
+const cacheEntryOverhead = 128
+
+// cacheShardMinBytes is the smallest share of MaxBytes a shard gets, room
+// for several typical greetings. Byte-bounded caches use fewer shards
+// rather than ones too small to hold anything.
+const cacheShardMinBytes = 8 * cacheEntryOverhead
+
+// CacheKey identifies a rendered greeting. It holds everything the
+// greeting is rendered from, so greeters with different catalogs can
+// share a cache without seeing each other's greetings.
+type CacheKey struct {
+	Name      string
+	Locale    string // the LocaleGreeter's canonical tag
+	Template  string // template source
+	Punct     string // the {punct} value, which comes from the catalog
+	Formality Formality
+}
+
+// CacheConfig bounds a GreetingCache. Zero values mean no limit, except
+// for Shards and Clock.
+type CacheConfig struct {
+	MaxEntries int           // most greetings kept
+	MaxBytes   int           // approximate memory bound, counting names and greetings
+	TTL        time.Duration // how long a greeting stays valid
+	Shards     int           // independently locked partitions, rounded up to a power of two, then lowered so each shard's share of the limits is useful; default scales with GOMAXPROCS
+	Clock      Clock         // time source for TTLs; default SystemClock
+}
+
+// CacheStats is a snapshot of a GreetingCache's counters.
+type CacheStats struct {
+	Hits        uint64
+	Misses      uint64
+	Evictions   uint64 // entries dropped to respect MaxEntries or MaxBytes
+	Expirations uint64 // entries dropped because their TTL passed
+	Entries     int
+	Bytes       int
+}
+
+// GreetingCache is a concurrent, size-bounded LRU cache of rendered
+// greetings. Keys are spread over shards with their own locks and LRU
+// lists, so goroutines greeting different names rarely contend.
+//
+// Example:
+//
+//	cache := NewGreetingCache(CacheConfig{MaxEntries: 10000, TTL: time.Hour})
+//	g, err := NewGreeter("de", WithCache(cache))
+//
+// Thread Safety:
+//   A GreetingCache is safe for concurrent use by multiple goroutines and
+//   may be shared by several Greeters.
+type GreetingCache struct {
+	seed   maphash.Seed
+	shards []cacheShard
+	mask   uint64
+	ttl    time.Duration
+	clock  Clock
+}
+
+type cacheShard struct {
+	mu         sync.Mutex
+	entries    map[CacheKey]*list.Element
+	lru        list.List // front is most recently used
+	maxEntries int
+	maxBytes   int
+	bytes      int
+	stats      CacheStats
+
+	// Pad shards apart so their locks do not share a cache line.
+	_ [64]byte
+}
+
+type cacheEntry struct {
+	key      CacheKey
+	greeting string
+	size     int
+	expires  time.Time
+}
+
+// NewGreetingCache returns an empty cache bounded by cfg.
+func NewGreetingCache(cfg CacheConfig) *GreetingCache {
+	shards := cfg.Shards
+	if shards < 1 {
+		shards = 4 * runtime.GOMAXPROCS(0)
+	}
+	n := 1
+	for n < shards {
+		n <<= 1
+	}
+	// The limits are split between the shards, so a shard must be able to
+	// hold at least one entry, and cacheShardMinBytes.
+	for n > 1 && ((cfg.MaxEntries > 0 && n > cfg.MaxEntries) || (cfg.MaxBytes > 0 && n > cfg.MaxBytes/cacheShardMinBytes)) {
+		n >>= 1
+	}
+
+	c := &GreetingCache{
+		seed:   maphash.MakeSeed(),
+		shards: make([]cacheShard, n),
+		mask:   uint64(n - 1),
+		ttl:    cfg.TTL,
+		clock:  cfg.Clock,
+	}
+	if c.clock == nil {
+		c.clock = SystemClock
+	}
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.entries = make(map[CacheKey]*list.Element)
+		s.maxEntries = perShard(cfg.MaxEntries, i, n)
+		s.maxBytes = perShard(cfg.MaxBytes, i, n)
+	}
+	return c
+}
+
+// perShard returns shard i's share of a limit split over n shards. The
+// shares add up to the limit exactly, so the cache as a whole never holds
+// more than it.
+func perShard(limit, i, n int) int {
+	if limit <= 0 {
+		return 0
+	}
+	share := limit / n
+	if i < limit%n {
+		share++
+	}
+	return share
+}
+
+func (c *GreetingCache) shard(key *CacheKey) *cacheShard {
+	var h maphash.Hash
+	h.SetSeed(c.seed)
+	h.WriteString(key.Name)
+	h.WriteString(key.Locale)
+	h.WriteString(key.Template)
+	h.WriteString(key.Punct)
+	h.WriteByte(byte(key.Formality))
+	return &c.shards[h.Sum64()&c.mask]
+}
+
+// Get returns the cached greeting for key, if present and not expired.
+func (c *GreetingCache) Get(key CacheKey) (string, bool) {
+	s := c.shard(&key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.entries[key]
+	if !ok {
+		s.stats.Misses++
+		return "", false
+	}
+	e := el.Value.(*cacheEntry)
+	if c.ttl > 0 && !c.clock.Now().Before(e.expires) {
+		s.remove(el)
+		s.stats.Expirations++
+		s.stats.Misses++
+		return "", false
+	}
+	s.lru.MoveToFront(el)
+	s.stats.Hits++
+	return e.greeting, true
+}
+
+// Set caches greeting under key, evicting the least recently used entries
+// of its shard as needed. A greeting larger than the byte limit of a shard
+// is not cached.
+func (c *GreetingCache) Set(key CacheKey, greeting string) {
+	size := len(key.Name) + len(greeting) + cacheEntryOverhead
+	var expires time.Time
+	if c.ttl > 0 {
+		expires = c.clock.Now().Add(c.ttl)
+	}
+
+	s := c.shard(&key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.maxBytes > 0 && size > s.maxBytes {
+		return
+	}
+	if el, ok := s.entries[key]; ok {
+		s.remove(el)
+	}
+	e := &cacheEntry{key: key, greeting: greeting, size: size, expires: expires}
+	s.entries[key] = s.lru.PushFront(e)
+	s.bytes += size
+
+	for (s.maxEntries > 0 && len(s.entries) > s.maxEntries) || (s.maxBytes > 0 && s.bytes > s.maxBytes) {
+		s.remove(s.lru.Back())
+		s.stats.Evictions++
+	}
+}
+
+// GetOrCompute returns the cached greeting for key, or computes, caches
+// and returns it. Errors from compute are returned and not cached.
+func (c *GreetingCache) GetOrCompute(key CacheKey, compute func() (string, error)) (string, error) {
+	if greeting, ok := c.Get(key); ok {
+		return greeting, nil
+	}
+	greeting, err := compute()
+	if err != nil {
+		return "", err
+	}
+	c.Set(key, greeting)
+	return greeting, nil
+}
+
+// Purge empties the cache. Counters are kept.
+func (c *GreetingCache) Purge() {
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		s.entries = make(map[CacheKey]*list.Element)
+		s.lru.Init()
+		s.bytes = 0
+		s.mu.Unlock()
+	}
+}
+
+// Stats returns the cache's counters, summed over its shards.
+func (c *GreetingCache) Stats() CacheStats {
+	var st CacheStats
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		st.Hits += s.stats.Hits
+		st.Misses += s.stats.Misses
+		st.Evictions += s.stats.Evictions
+		st.Expirations += s.stats.Expirations
+		st.Entries += len(s.entries)
+		st.Bytes += s.bytes
+		s.mu.Unlock()
+	}
+	return st
+}
+
+func (s *cacheShard) remove(el *list.Element) {
+	e := s.lru.Remove(el).(*cacheEntry)
+	delete(s.entries, e.key)
+	s.bytes -= e.size
+}
+
+// WithCache makes SayHi and SayHiStrict look greetings up in c before
+// rendering them. Greetings in time-of-day mode depend on the clock and
+// are never cached. Greeters with different catalogs may share a cache.
+func WithCache(c *GreetingCache) Option {
+	return func(cfg *greeterConfig) {
+		cfg.cache = c
+	}
+}
@@ -0,0 +1,222 @@
+package test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock tests advance by hand
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func cacheKeyFor(name string) CacheKey {
+	return CacheKey{Name: name, Locale: "en", Template: "Hi, {name}"}
+}
+
+// TestGreetingCacheLRU tests least-recently-used eviction
+func TestGreetingCacheLRU(t *testing.T) {
+	c := NewGreetingCache(CacheConfig{MaxEntries: 2, Shards: 1})
+	c.Set(cacheKeyFor("a"), "Hi, a")
+	c.Set(cacheKeyFor("b"), "Hi, b")
+	if _, ok := c.Get(cacheKeyFor("a")); !ok { // a is now more recent than b
+		t.Fatal("a missing")
+	}
+	c.Set(cacheKeyFor("c"), "Hi, c")
+
+	if _, ok := c.Get(cacheKeyFor("b")); ok {
+		t.Error("b was not evicted")
+	}
+	for _, name := range []string{"a", "c"} {
+		if greeting, ok := c.Get(cacheKeyFor(name)); !ok || greeting != "Hi, "+name {
+			t.Errorf("Get(%q) = %q, %v", name, greeting, ok)
+		}
+	}
+
+	st := c.Stats()
+	if st.Hits != 3 || st.Misses != 1 || st.Evictions != 1 || st.Entries != 2 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+// TestGreetingCacheTTL tests expiry with an injected clock
+func TestGreetingCacheTTL(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c := NewGreetingCache(CacheConfig{TTL: time.Minute, Clock: clock})
+
+	c.Set(cacheKeyFor("a"), "Hi, a")
+	clock.Advance(59 * time.Second)
+	if _, ok := c.Get(cacheKeyFor("a")); !ok {
+		t.Error("entry expired early")
+	}
+	clock.Advance(time.Second)
+	if _, ok := c.Get(cacheKeyFor("a")); ok {
+		t.Error("entry did not expire")
+	}
+	if st := c.Stats(); st.Expirations != 1 || st.Entries != 0 || st.Bytes != 0 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+// TestGreetingCacheBytes tests the memory bound
+func TestGreetingCacheBytes(t *testing.T) {
+	limit := 4 * (cacheEntryOverhead + 20)
+	c := NewGreetingCache(CacheConfig{MaxBytes: limit, Shards: 1})
+	for i := 0; i < 100; i++ {
+		name := fmt.Sprintf("name%04d", i)
+		c.Set(cacheKeyFor(name), "Hi, "+name)
+	}
+	if st := c.Stats(); st.Bytes > limit || st.Entries == 0 || st.Evictions == 0 {
+		t.Errorf("stats = %+v, want at most %d bytes", st, limit)
+	}
+
+	huge := string(make([]byte, limit))
+	c.Set(cacheKeyFor("huge"), huge)
+	if _, ok := c.Get(cacheKeyFor("huge")); ok {
+		t.Error("cached an entry larger than the byte limit")
+	}
+}
+
+// TestGreetingCacheLimits tests that small limits hold for the cache as a
+// whole, however many shards are asked for
+func TestGreetingCacheLimits(t *testing.T) {
+	tests := []struct {
+		cfg     CacheConfig
+		entries int
+	}{
+		{CacheConfig{MaxEntries: 10}, 10},
+		{CacheConfig{MaxEntries: 10, Shards: 64}, 10},
+		{CacheConfig{MaxEntries: 1, Shards: 64}, 1},
+		{CacheConfig{MaxEntries: 100, MaxBytes: 3 * cacheShardMinBytes, Shards: 64}, 0},
+	}
+
+	for _, tt := range tests {
+		c := NewGreetingCache(tt.cfg)
+		for i := 0; i < 1000; i++ {
+			name := fmt.Sprintf("name%04d", i)
+			c.Set(cacheKeyFor(name), "Hi, "+name)
+		}
+		st := c.Stats()
+		switch {
+		case st.Entries > tt.cfg.MaxEntries:
+			t.Errorf("%+v: holds %d entries, want at most %d", tt.cfg, st.Entries, tt.cfg.MaxEntries)
+		case tt.entries > 0 && st.Entries != tt.entries:
+			t.Errorf("%+v: holds %d entries, want %d once full", tt.cfg, st.Entries, tt.entries)
+		case tt.cfg.MaxBytes > 0 && (st.Bytes > tt.cfg.MaxBytes || st.Entries == 0):
+			t.Errorf("%+v: holds %d bytes in %d entries, want at most %d bytes", tt.cfg, st.Bytes, st.Entries, tt.cfg.MaxBytes)
+		}
+	}
+}
+
+// TestGreeterCache tests that greeters share and bypass the cache correctly
+func TestGreeterCache(t *testing.T) {
+	c := NewGreetingCache(CacheConfig{MaxEntries: 100})
+	en, _ := NewGreeter("en", WithCache(c))
+	de, _ := NewGreeter("de", WithCache(c))
+	formal, _ := NewGreeter("de", WithCache(c), WithFormality(Formal))
+
+	for i := 0; i < 3; i++ {
+		if en.SayHi("Alice") != "Hi, Alice" || de.SayHi("Alice") != "Hallo, Alice" || formal.SayHi("Alice") != "Guten Tag, Alice" {
+			t.Fatal("cached greetings mixed up locales or formality")
+		}
+	}
+	if greeting, err := en.SayHiStrict("  Alice "); err != nil || greeting != "Hi, Alice" {
+		t.Errorf("SayHiStrict = %q, %v", greeting, err)
+	}
+	if st := c.Stats(); st.Misses != 3 || st.Hits != 7 {
+		t.Errorf("stats = %+v, want 3 misses and 7 hits", st)
+	}
+
+	timed, _ := NewGreeter("en", WithCache(c), WithTimeOfDay(ClockFunc(func() time.Time { return at(20, 0) })))
+	timed.SayHi("Bob")
+	if st := c.Stats(); st.Entries != 3 {
+		t.Errorf("time-of-day greeting was cached: %+v", st)
+	}
+}
+
+// TestGreeterCacheCatalogs tests sharing a cache between greeters with different catalogs
+func TestGreeterCacheCatalogs(t *testing.T) {
+	c := NewGreetingCache(CacheConfig{MaxEntries: 100})
+	var greeters []*LocaleGreeter
+	for _, punct := range []string{", ", " dear "} {
+		catalog := NewCatalog()
+		if err := catalog.Set("en", MessageGreeting, "Hello{punct}{name}"); err != nil {
+			t.Fatal(err)
+		}
+		if err := catalog.Set("en", MessagePunct, punct); err != nil {
+			t.Fatal(err)
+		}
+		g, err := NewGreeter("en", WithCatalog(catalog), WithCache(c))
+		if err != nil {
+			t.Fatal(err)
+		}
+		greeters = append(greeters, g)
+	}
+
+	for i := 0; i < 2; i++ {
+		if a, b := greeters[0].SayHi("Ann"), greeters[1].SayHi("Ann"); a != "Hello, Ann" || b != "Hello dear Ann" {
+			t.Errorf("greeters sharing a cache greeted %q and %q", a, b)
+		}
+	}
+}
+
+// TestGreetingCacheConcurrent tests the cache under concurrent use
+func TestGreetingCacheConcurrent(t *testing.T) {
+	c := NewGreetingCache(CacheConfig{MaxEntries: 64})
+	g, _ := NewGreeter("en", WithCache(c))
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				name := fmt.Sprint("name", (i*7+w)%200)
+				if greeting := g.SayHi(name); greeting != "Hi, "+name {
+					t.Errorf("SayHi(%q) = %q", name, greeting)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	st := c.Stats()
+	if st.Hits+st.Misses != 8000 || st.Entries > 64+len(c.shards) {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+// BenchmarkGreetingCacheParallel benchmarks cache hits under contention
+func BenchmarkGreetingCacheParallel(b *testing.B) {
+	c := NewGreetingCache(CacheConfig{MaxEntries: 10000})
+	g, _ := NewGreeter("en", WithCache(c))
+	names := make([]string, 1000)
+	for i := range names {
+		names[i] = fmt.Sprint("name", i)
+		g.SayHi(names[i])
+	}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			g.SayHi(names[i%len(names)])
+			i++
+		}
+	})
+}
@@ -0,0 +1,180 @@
+package test
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Catalog message keys.
+const (
+	// MessageGreeting holds the informal greeting template, e.g. "Hi, {name}".
+	MessageGreeting = "greeting"
+
+	// MessageTimeOfDay holds the greeting template used in time-of-day
+	// mode, e.g. "{timeofday}{punct}{name}". The salutation for each
+	// DayPeriod is stored under the period's name, e.g. "morning".
+	MessageTimeOfDay = "greeting.timeofday"
+
+	// MessageDayPeriods holds the locale's day period boundaries in the
+	// format accepted by ParseDayPeriods, e.g. "05:00 12:00 18:00 22:00".
+	MessageDayPeriods = "dayperiods"
+
+	// MessagePunct holds the locale's greeting separator including any
+	// spacing, rendered by the {punct} placeholder, e.g. ", " or "、".
+	MessagePunct = "punct"
+)
+
+// Built-in message files, one JSON object per locale named after its tag.
+//
+//go:embed locales/*.json
+var embeddedLocales embed.FS
+
+var (
+	builtinOnce    sync.Once
+	builtinCatalog *Catalog
+)
+
+// Catalog is a set of localized messages keyed by language tag and message
+// key. Lookups walk the tag's fallback chain, so a message missing from
+// "pt-BR" is served from "pt" and finally from DefaultLocale.
+//
+// Thread Safety:
+//   A Catalog is safe for concurrent use by multiple goroutines.
+type Catalog struct {
+	mu       sync.RWMutex
+	messages map[string]map[string]string
+}
+
+// NewCatalog returns an empty catalog.
+func NewCatalog() *Catalog {
+	return &Catalog{messages: make(map[string]map[string]string)}
+}
+
+// DefaultCatalog returns a new catalog populated with the built-in locales.
+// The returned catalog is independent of the one used by greeters created
+// without WithCatalog, so it can be extended with LoadDir or Set freely.
+func DefaultCatalog() *Catalog {
+	c := NewCatalog()
+	if err := c.LoadFS(embeddedLocales, "locales"); err != nil {
+		panic("test: loading built-in locales: " + err.Error())
+	}
+	return c
+}
+
+// defaultCatalog returns the shared, read-only built-in catalog.
+func defaultCatalog() *Catalog {
+	builtinOnce.Do(func() {
+		builtinCatalog = DefaultCatalog()
+	})
+	return builtinCatalog
+}
+
+// Set stores a single message for the given tag and key.
+func (c *Catalog) Set(tag, key, message string) error {
+	canon, err := CanonicalTag(tag)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(canon, map[string]string{key: message})
+	return nil
+}
+
+// LoadFS loads every "<tag>.json" file in dir of fsys. Each file holds a
+// flat JSON object mapping message keys to messages. Messages from later
+// loads override earlier ones key by key.
+func (c *Catalog) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	loaded := make(map[string]map[string]string, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		name := entry.Name()
+		tag, err := CanonicalTag(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			return fmt.Errorf("catalog file %s: %w", name, err)
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return err
+		}
+
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return fmt.Errorf("catalog file %s: %w", name, err)
+		}
+		loaded[tag] = messages
+	}
+
+	// Only merge once every file parsed, so a bad file leaves c untouched.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for tag, messages := range loaded {
+		c.setLocked(tag, messages)
+	}
+	return nil
+}
+
+// LoadDir loads every "<tag>.json" file from a directory on disk.
+// See LoadFS for the file format.
+func (c *Catalog) LoadDir(dir string) error {
+	return c.LoadFS(os.DirFS(dir), ".")
+}
+
+// Lookup returns the message for key, walking the fallback chain of tag.
+// It also reports the tag the message was found under.
+func (c *Catalog) Lookup(tag, key string) (message, foundIn string, ok bool) {
+	canon, err := CanonicalTag(tag)
+	if err != nil {
+		canon = DefaultLocale
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, t := range FallbackChain(canon) {
+		if msg, ok := c.messages[t][key]; ok {
+			return msg, t, true
+		}
+	}
+	return "", "", false
+}
+
+// Locales returns the sorted tags that have at least one message.
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	tags := make([]string, 0, len(c.messages))
+	for tag := range c.messages {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+func (c *Catalog) setLocked(tag string, messages map[string]string) {
+	m := c.messages[tag]
+	if m == nil {
+		m = make(map[string]string, len(messages))
+		c.messages[tag] = m
+	}
+	for k, v := range messages {
+		m[k] = v
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/zhangbaodong/test"
+)
+
+// maxLine is the longest input line batch accepts.
+const maxLine = 1 << 20
+
+var batchCommand = &command{
+	name:    "batch",
+	args:    "[FILE]",
+	summary: "Greet one name per line of FILE or standard input",
+	flags: func(fs *flag.FlagSet) runFunc {
+		gf := addGreeterFlags(fs)
+		workers := fs.Int("workers", 0, "number of concurrent workers (default GOMAXPROCS)")
+		return func(e *env, args []string) error {
+			if len(args) > 1 {
+				return usageErrorf("at most one input file may be given")
+			}
+			g, err := gf.greeter()
+			if err != nil {
+				return err
+			}
+
+			in := e.stdin
+			if len(args) == 1 && args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				in = f
+			}
+
+			out := newRecordWriter(gf.format, gf.escape, e.stdout, e.stderr, "line")
+			total, failed, err := greetBatch(e, g, *workers, in, out)
+			if closeErr := out.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+			if failed > 0 {
+				return &exitError{code: exitPartial, err: fmt.Errorf("%d of %d names rejected", failed, total)}
+			}
+			return nil
+		}
+	},
+}
+
+// greetBatch greets the non-blank lines of r with g on a BatchGreeter and
+// writes a record per line to out, in input order.
+func greetBatch(e *env, g *test.LocaleGreeter, workers int, r io.Reader, out recordWriter) (total, failed int, err error) {
+	ctx, cancel := context.WithCancel(e.ctx)
+	defer cancel()
+
+	names := make(chan string)
+	readErr := make(chan error, 1)
+
+	// lines maps a record's position in the batch to its line number.
+	var mu sync.Mutex
+	lines := make(map[int]int)
+
+	go func() {
+		defer close(names)
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, maxLine)
+		for line, n := 1, 0; scanner.Scan(); line++ {
+			name := scanner.Text()
+			if strings.TrimSpace(name) == "" {
+				continue
+			}
+			mu.Lock()
+			lines[n] = line
+			mu.Unlock()
+			n++
+			select {
+			case names <- name:
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	for res := range test.NewBatchGreeter(g.SayHiStrict, workers).Greet(ctx, names) {
+		mu.Lock()
+		line := lines[res.Index]
+		delete(lines, res.Index)
+		mu.Unlock()
+
+		rec := record{Index: line, Name: res.Name, Locale: g.Locale(), Greeting: res.Greeting}
+		total++
+		if res.Err != nil {
+			rec.Error = res.Err.Error()
+			failed++
+		}
+		if err := out.Write(rec); err != nil {
+			return total, failed, err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return total, failed, err
+	}
+	return total, failed, <-readErr
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/zhangbaodong/test"
+)
+
+var shells = []string{"bash", "zsh", "fish"}
+
+var completionCommand = &command{
+	name:    "completion",
+	args:    "bash|zsh|fish",
+	summary: "Print a shell completion script",
+	flags: func(fs *flag.FlagSet) runFunc {
+		return func(e *env, args []string) error {
+			if len(args) != 1 {
+				return usageErrorf("exactly one shell must be given")
+			}
+			switch args[0] {
+			case "bash":
+				writeBashCompletion(e.stdout)
+			case "zsh":
+				writeZshCompletion(e.stdout)
+			case "fish":
+				writeFishCompletion(e.stdout)
+			default:
+				return usageErrorf("unsupported shell %q; want one of %s", args[0], strings.Join(shells, ", "))
+			}
+			return nil
+		}
+	},
+}
+
+// flagSpec describes a flag for completion.
+type flagSpec struct {
+	name   string
+	usage  string
+	isBool bool
+	values []string // candidate values, if the flag has a fixed set
+}
+
+// flagSpecs returns the flags cmd accepts, sorted by name.
+func flagSpecs(cmd *command) []flagSpec {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	cmd.flags(fs)
+
+	var specs []flagSpec
+	fs.VisitAll(func(f *flag.Flag) {
+		_, usage := flag.UnquoteUsage(f)
+		b, ok := f.Value.(interface{ IsBoolFlag() bool })
+		spec := flagSpec{name: f.Name, usage: usage, isBool: ok && b.IsBoolFlag()}
+		switch f.Name {
+		case "format":
+			spec.values = formats
+		case "escape":
+			spec.values = encodings
+		case "locale":
+			spec.values = test.DefaultCatalog().Locales()
+		}
+		specs = append(specs, spec)
+	})
+	sort.Slice(specs, func(i, j int) bool { return specs[i].name < specs[j].name })
+	return specs
+}
+
+// argValues returns the fixed candidates for cmd's positional arguments,
+// and whether they are file names instead.
+func argValues(cmd *command) (values []string, files bool) {
+	switch cmd.name {
+	case "batch":
+		return nil, true
+	case "completion":
+		return shells, false
+	}
+	return nil, false
+}
+
+func commandNames() []string {
+	names := make([]string, len(commands))
+	for i, cmd := range commands {
+		names[i] = cmd.name
+	}
+	return names
+}
+
+func writeBashCompletion(w io.Writer) {
+	fmt.Fprintf(w, "# bash completion for greet; load with: source <(greet completion bash)\n")
+	fmt.Fprintf(w, "_greet() {\n")
+	fmt.Fprintf(w, "    local cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	fmt.Fprintf(w, "    if [ \"$COMP_CWORD\" -eq 1 ]; then\n")
+	fmt.Fprintf(w, "        COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", strings.Join(commandNames(), " "))
+	fmt.Fprintf(w, "        return\n")
+	fmt.Fprintf(w, "    fi\n")
+	fmt.Fprintf(w, "    case \"${COMP_WORDS[1]}\" in\n")
+	for _, cmd := range commands {
+		specs := flagSpecs(cmd)
+		var flags []string
+		for _, f := range specs {
+			flags = append(flags, "-"+f.name)
+		}
+		fmt.Fprintf(w, "    %s)\n", cmd.name)
+		fmt.Fprintf(w, "        case \"$prev\" in\n")
+		for _, f := range specs {
+			switch {
+			case f.isBool:
+			case f.values != nil:
+				fmt.Fprintf(w, "            -%s) COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")); return ;;\n", f.name, strings.Join(f.values, " "))
+			default:
+				fmt.Fprintf(w, "            -%s) return ;;\n", f.name)
+			}
+		}
+		fmt.Fprintf(w, "        esac\n")
+		values, files := argValues(cmd)
+		action := ""
+		if files {
+			action = "-f "
+		}
+		words := strings.Join(append(flags, values...), " ")
+		fmt.Fprintf(w, "        COMPREPLY=($(compgen %s-W \"%s\" -- \"$cur\")) ;;\n", action, words)
+	}
+	fmt.Fprintf(w, "    esac\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "complete -F _greet greet\n")
+}
+
+func writeZshCompletion(w io.Writer) {
+	fmt.Fprintf(w, "#compdef greet\n")
+	fmt.Fprintf(w, "# zsh completion for greet; load with: source <(greet completion zsh)\n")
+	fmt.Fprintf(w, "_greet() {\n")
+	fmt.Fprintf(w, "    local -a commands\n")
+	fmt.Fprintf(w, "    commands=(\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "        %s\n", zshQuote(cmd.name+":"+cmd.summary))
+	}
+	fmt.Fprintf(w, "    )\n")
+	fmt.Fprintf(w, "    if (( CURRENT == 2 )); then\n")
+	fmt.Fprintf(w, "        _describe command commands\n")
+	fmt.Fprintf(w, "        return\n")
+	fmt.Fprintf(w, "    fi\n")
+	fmt.Fprintf(w, "    case $words[2] in\n")
+	for _, cmd := range commands {
+		var specs []string
+		for _, f := range flagSpecs(cmd) {
+			spec := "-" + f.name + "[" + zshEscape(f.usage) + "]"
+			switch {
+			case f.isBool:
+			case f.values != nil:
+				spec += ":" + f.name + ":(" + strings.Join(f.values, " ") + ")"
+			default:
+				spec += ":" + f.name + ":"
+			}
+			specs = append(specs, zshQuote(spec))
+		}
+		switch values, files := argValues(cmd); {
+		case files:
+			specs = append(specs, zshQuote("*:file:_files"))
+		case values != nil:
+			specs = append(specs, zshQuote("1:"+cmd.args+":("+strings.Join(values, " ")+")"))
+		}
+		fmt.Fprintf(w, "    %s)\n", cmd.name)
+		if len(specs) > 0 {
+			fmt.Fprintf(w, "        _arguments %s ;;\n", strings.Join(specs, " "))
+		} else {
+			fmt.Fprintf(w, "        ;;\n")
+		}
+	}
+	fmt.Fprintf(w, "    esac\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "compdef _greet greet\n")
+}
+
+func writeFishCompletion(w io.Writer) {
+	fmt.Fprintf(w, "# fish completion for greet; load with: greet completion fish | source\n")
+	fmt.Fprintf(w, "complete -c greet -f\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "complete -c greet -n __fish_use_subcommand -a %s -d %s\n", cmd.name, fishQuote(cmd.summary))
+	}
+	for _, cmd := range commands {
+		cond := fishQuote("__fish_seen_subcommand_from " + cmd.name)
+		for _, f := range flagSpecs(cmd) {
+			line := fmt.Sprintf("complete -c greet -n %s -o %s -d %s", cond, f.name, fishQuote(f.usage))
+			switch {
+			case f.isBool:
+			case f.values != nil:
+				line += " -x -a " + fishQuote(strings.Join(f.values, " "))
+			default:
+				line += " -x"
+			}
+			fmt.Fprintln(w, line)
+		}
+		switch values, files := argValues(cmd); {
+		case files:
+			fmt.Fprintf(w, "complete -c greet -n %s -F\n", cond)
+		case values != nil:
+			fmt.Fprintf(w, "complete -c greet -n %s -a %s\n", cond, fishQuote(strings.Join(values, " ")))
+		}
+	}
+}
+
+// zshQuote single-quotes s for zsh.
+func zshQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// zshEscape escapes the characters _arguments treats specially in a
+// description.
+func zshEscape(s string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`, ":", `\:`).Replace(s)
+}
+
+// fishQuote single-quotes s for fish.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/zhangbaodong/test"
+)
+
+var localesCommand = &command{
+	name:    "locales",
+	summary: "List the built-in locales",
+	flags: func(fs *flag.FlagSet) runFunc {
+		format := formatFlag(formatText)
+		fs.Var(&format, "format", "output `format`: text, json, ndjson or csv")
+		return func(e *env, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("unexpected arguments %q", args)
+			}
+
+			out := newRecordWriter(format, encodingFlag(test.EncodingRaw), e.stdout, e.stderr, "locale")
+			for i, tag := range test.DefaultCatalog().Locales() {
+				g, err := test.NewGreeter(tag)
+				if err != nil {
+					return fmt.Errorf("locale %s: %w", tag, err)
+				}
+				r := record{Index: i + 1, Name: "World", Locale: tag, Greeting: g.SayHi("World")}
+				if format == formatText {
+					r.Greeting = fmt.Sprintf("%-6s %s", tag, r.Greeting)
+				}
+				if err := out.Write(r); err != nil {
+					return err
+				}
+			}
+			return out.Close()
+		}
+	},
+}
@@ -0,0 +1,188 @@
+// Command greet generates greetings from the command line.
+//
+// Usage:
+//
+//	greet say [flags] NAME...
+//	greet batch [flags] [FILE]
+//	greet serve [flags]
+//	greet locales [flags]
+//	greet completion bash|zsh|fish
+//	greet version
+//
+// Exit codes:
+//
+//	0  success
+//	1  runtime failure, such as an I/O or server error
+//	2  usage error
+//	3  invalid input: a name or locale was rejected
+//	4  partial failure: some batch records were rejected
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Exit codes, one per failure class.
+const (
+	exitOK           = 0
+	exitFailure      = 1
+	exitUsage        = 2
+	exitInvalidInput = 3
+	exitPartial      = 4
+)
+
+// exitError carries the exit code for an error returned by a command.
+// Errors of any other type exit with exitFailure.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+func usageErrorf(format string, args ...interface{}) error {
+	return &exitError{code: exitUsage, err: fmt.Errorf(format, args...)}
+}
+
+func invalidInput(err error) error {
+	return &exitError{code: exitInvalidInput, err: err}
+}
+
+// env is what a command may use of the outside world, so tests can run
+// commands in-process.
+type env struct {
+	ctx    context.Context
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// runFunc runs a command with its positional arguments after flag parsing.
+type runFunc func(e *env, args []string) error
+
+// command is a greet subcommand.
+type command struct {
+	name    string
+	args    string // synopsis of the positional arguments
+	summary string
+
+	// flags registers the command's flags on fs and returns the function
+	// that runs it with their parsed values. Shell completion calls it
+	// only to discover the flags.
+	flags func(fs *flag.FlagSet) runFunc
+}
+
+// commands lists the subcommands in the order usage shows them.
+var commands []*command
+
+func init() {
+	commands = []*command{
+		sayCommand,
+		batchCommand,
+		serveCommand,
+		localesCommand,
+		completionCommand,
+		versionCommand,
+	}
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(&env{ctx: ctx, stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}, os.Args[1:])
+	stop()
+	os.Exit(code)
+}
+
+// run executes the command line args and returns the exit code.
+func run(e *env, args []string) int {
+	if len(args) == 0 {
+		usage(e.stderr)
+		return exitUsage
+	}
+	switch args[0] {
+	case "-h", "-help", "--help", "help":
+		usage(e.stdout)
+		return exitOK
+	case "-version", "--version":
+		args = []string{"version"}
+	}
+
+	cmd := lookupCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(e.stderr, "greet: unknown command %q\n\n", args[0])
+		usage(e.stderr)
+		return exitUsage
+	}
+
+	fs := flag.NewFlagSet("greet "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "Usage: greet %s [flags] %s\n\n%s.\n", cmd.name, cmd.args, cmd.summary)
+		if hasFlags(fs) {
+			fmt.Fprintf(e.stderr, "\nFlags:\n")
+			fs.PrintDefaults()
+		}
+	}
+	runCmd := cmd.flags(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	err := runCmd(e, fs.Args())
+	if err == nil {
+		return exitOK
+	}
+	fmt.Fprintf(e.stderr, "greet %s: %v\n", cmd.name, err)
+	var exit *exitError
+	if errors.As(err, &exit) {
+		if exit.code == exitUsage {
+			fs.Usage()
+		}
+		return exit.code
+	}
+	return exitFailure
+}
+
+func lookupCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+func hasFlags(fs *flag.FlagSet) bool {
+	n := 0
+	fs.VisitAll(func(*flag.Flag) { n++ })
+	return n > 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: greet <command> [flags] [arguments]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-11s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, "\nRun 'greet <command> -h' for the flags of a command.\n")
+	fmt.Fprintf(w, "\nExamples:\n")
+	fmt.Fprintf(w, "  greet say Alice\n")
+	fmt.Fprintf(w, "  greet say -locale de -format json Anna\n")
+	fmt.Fprintf(w, "  greet batch -format csv names.txt > greetings.csv\n")
+	fmt.Fprintf(w, "  greet serve -addr :8080\n")
+}
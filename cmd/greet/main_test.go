@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runCLI runs greet in-process with the given stdin
+func runCLI(stdin string, args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	e := &env{ctx: context.Background(), stdin: strings.NewReader(stdin), stdout: &out, stderr: &errOut}
+	code = run(e, args)
+	return code, out.String(), errOut.String()
+}
+
+// TestSay tests the say command across formats and exit codes
+func TestSay(t *testing.T) {
+	tests := []struct {
+		args   []string
+		code   int
+		stdout string
+	}{
+		{[]string{"say", "Alice"}, exitOK, "Hi, Alice\n"},
+		{[]string{"say", "-locale", "pt_BR", "Alice", " Bob "}, exitOK, "Oi, Alice\nOi, Bob\n"},
+		{[]string{"say", "-format", "ndjson", "Alice"}, exitOK, `{"index":1,"name":"Alice","locale":"en","greeting":"Hi, Alice"}` + "\n"},
+		{[]string{"say", "-escape", "shell", "O'Brien"}, exitOK, `'Hi, O'\''Brien'` + "\n"},
+		{[]string{"say", "-escape", "html", "<b>"}, exitOK, "Hi, &lt;b&gt;\n"},
+		{[]string{"say", "Alice", "Eve\x00"}, exitInvalidInput, "Hi, Alice\n"},
+		{[]string{"say", "-locale", "not a tag", "Alice"}, exitInvalidInput, ""},
+		{[]string{"say"}, exitUsage, ""},
+		{[]string{"say", "-format", "xml", "Alice"}, exitUsage, ""},
+		{[]string{"say", "-tz", "UTC", "Alice"}, exitUsage, ""},
+		{[]string{"say", "-escape", "xml", "Alice"}, exitUsage, ""},
+		{[]string{"say", "-escape", "json", "-format", "csv", "Alice"}, exitUsage, ""},
+		{[]string{"shout", "Alice"}, exitUsage, ""},
+		{[]string{}, exitUsage, ""},
+	}
+
+	for _, tt := range tests {
+		code, stdout, stderr := runCLI("", tt.args...)
+		if code != tt.code || stdout != tt.stdout {
+			t.Errorf("greet %q = %d %q, want %d %q (stderr %q)", tt.args, code, stdout, tt.code, tt.stdout, stderr)
+		}
+	}
+}
+
+// TestSayJSON tests that JSON output is a valid array including failures
+func TestSayJSON(t *testing.T) {
+	code, stdout, _ := runCLI("", "say", "-format", "json", "Alice", "")
+	var records []record
+	if err := json.Unmarshal([]byte(stdout), &records); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if code != exitInvalidInput || len(records) != 2 || records[0].Greeting != "Hi, Alice" || records[1].Error == "" {
+		t.Errorf("say -format json = %d %+v", code, records)
+	}
+
+	if _, stdout, _ := runCLI("", "say", "-format", "json", "-locale", "xx-!", "Alice"); stdout != "" {
+		t.Errorf("output on invalid locale = %q, want none", stdout)
+	}
+}
+
+// TestBatch tests line-numbered batch output and partial failure
+func TestBatch(t *testing.T) {
+	input := "Alice\n\nBob\nEve\x00\n"
+
+	code, stdout, stderr := runCLI(input, "batch")
+	if code != exitPartial || stdout != "Hi, Alice\nHi, Bob\n" || !strings.Contains(stderr, "line 4:") {
+		t.Errorf("batch = %d %q, stderr %q", code, stdout, stderr)
+	}
+
+	code, stdout, _ = runCLI(input, "batch", "-format", "csv", "-workers", "2")
+	rows, err := csv.NewReader(strings.NewReader(stdout)).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV %q: %v", stdout, err)
+	}
+	want := [][]string{
+		recordHeader,
+		{"1", "Alice", "en", "Hi, Alice", ""},
+		{"3", "Bob", "en", "Hi, Bob", ""},
+	}
+	if code != exitPartial || len(rows) != 4 || rows[3][0] != "4" || rows[3][4] == "" {
+		t.Fatalf("batch -format csv = %d %q", code, rows)
+	}
+	for i, row := range want {
+		if strings.Join(rows[i], ",") != strings.Join(row, ",") {
+			t.Errorf("row %d = %q, want %q", i, rows[i], row)
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "names.txt")
+	if err := os.WriteFile(file, []byte("Alice\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if code, stdout, _ := runCLI("", "batch", "-locale", "es", file); code != exitOK || stdout != "Hola, Alice\n" {
+		t.Errorf("batch FILE = %d %q", code, stdout)
+	}
+	if code, _, _ := runCLI("", "batch", filepath.Join(t.TempDir(), "missing")); code != exitFailure {
+		t.Errorf("batch on missing file = %d, want %d", code, exitFailure)
+	}
+}
+
+// TestLocalesAndVersion tests the informational commands
+func TestLocalesAndVersion(t *testing.T) {
+	code, stdout, _ := runCLI("", "locales")
+	if code != exitOK || !strings.Contains(stdout, "pt-BR  Oi, World\n") {
+		t.Errorf("locales = %d %q", code, stdout)
+	}
+
+	version = "v1.2.3"
+	defer func() { version = "" }()
+	if code, stdout, _ := runCLI("", "--version"); code != exitOK || !strings.HasPrefix(stdout, "greet v1.2.3\n") {
+		t.Errorf("--version = %d %q", code, stdout)
+	}
+}
+
+// TestCompletion tests that every shell script mentions every command and flag
+func TestCompletion(t *testing.T) {
+	for _, shell := range shells {
+		code, stdout, _ := runCLI("", "completion", shell)
+		if code != exitOK {
+			t.Errorf("completion %s exited %d", shell, code)
+			continue
+		}
+		for _, cmd := range commands {
+			if !strings.Contains(stdout, cmd.name) {
+				t.Errorf("completion %s does not mention command %s", shell, cmd.name)
+			}
+			for _, f := range flagSpecs(cmd) {
+				if !strings.Contains(stdout, f.name) {
+					t.Errorf("completion %s does not mention flag -%s", shell, f.name)
+				}
+			}
+		}
+	}
+
+	if code, _, _ := runCLI("", "completion", "powershell"); code != exitUsage {
+		t.Errorf("completion powershell = %d, want %d", code, exitUsage)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/zhangbaodong/test"
+)
+
+// Output formats accepted by -format.
+const (
+	formatText   = "text"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+	formatCSV    = "csv"
+)
+
+var formats = []string{formatText, formatJSON, formatNDJSON, formatCSV}
+
+// record is one greeted (or rejected) name.
+type record struct {
+	Index    int    `json:"index"` // 1-based: the argument number for say, the line number for batch
+	Name     string `json:"name"`
+	Locale   string `json:"locale"`
+	Greeting string `json:"greeting,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+var recordHeader = []string{"index", "name", "locale", "greeting", "error"}
+
+// recordWriter writes records in one output format. Close must be called
+// to finish the document and flush buffered output.
+type recordWriter interface {
+	Write(r record) error
+	Close() error
+}
+
+// formatFlag is a flag.Value restricted to the supported formats.
+type formatFlag string
+
+func (f *formatFlag) String() string {
+	return string(*f)
+}
+
+func (f *formatFlag) Set(s string) error {
+	for _, format := range formats {
+		if s == format {
+			*f = formatFlag(s)
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(formats, ", "))
+}
+
+// encodings are the names accepted by -escape.
+var encodings = []string{"raw", "html", "json", "shell", "csv", "markdown"}
+
+// encodingFlag is a flag.Value holding a test.Encoding by name.
+type encodingFlag test.Encoding
+
+func (f *encodingFlag) String() string {
+	return test.Encoding(*f).String()
+}
+
+func (f *encodingFlag) Set(s string) error {
+	e, err := test.ParseEncoding(s)
+	if err != nil {
+		return fmt.Errorf("must be one of %s", strings.Join(encodings, ", "))
+	}
+	*f = encodingFlag(e)
+	return nil
+}
+
+// newRecordWriter returns a recordWriter for format. In text format only
+// greetings are written to w, escaped for escape; rejected records are
+// reported on errw, prefixed with label and their index.
+func newRecordWriter(format formatFlag, escape encodingFlag, w, errw io.Writer, label string) recordWriter {
+	bw := bufio.NewWriter(w)
+	switch format {
+	case formatJSON:
+		return &jsonWriter{w: bw}
+	case formatNDJSON:
+		return &ndjsonWriter{w: bw, enc: json.NewEncoder(bw)}
+	case formatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}
+	default:
+		return &textWriter{w: bw, errw: errw, label: label, escape: test.Encoding(escape)}
+	}
+}
+
+type textWriter struct {
+	w      *bufio.Writer
+	errw   io.Writer
+	label  string
+	escape test.Encoding
+	buf    []byte
+}
+
+func (t *textWriter) Write(r record) error {
+	if r.Error != "" {
+		_, err := fmt.Fprintf(t.errw, "%s %d: %s\n", t.label, r.Index, r.Error)
+		return err
+	}
+	t.buf = test.AppendEscaped(t.buf[:0], r.Greeting, t.escape)
+	t.w.Write(t.buf)
+	return t.w.WriteByte('\n')
+}
+
+func (t *textWriter) Close() error {
+	return t.w.Flush()
+}
+
+// jsonWriter streams records as a single JSON array.
+type jsonWriter struct {
+	w *bufio.Writer
+	n int
+}
+
+func (j *jsonWriter) Write(r record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if j.n == 0 {
+		j.w.WriteString("[\n  ")
+	} else {
+		j.w.WriteString(",\n  ")
+	}
+	j.n++
+	_, err = j.w.Write(b)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	if j.n == 0 {
+		j.w.WriteString("[")
+	}
+	j.w.WriteString("\n]\n")
+	return j.w.Flush()
+}
+
+type ndjsonWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(r record) error {
+	return n.enc.Encode(r)
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.w.Flush()
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func (c *csvWriter) Write(r record) error {
+	if !c.header {
+		c.header = true
+		if err := c.w.Write(recordHeader); err != nil {
+			return err
+		}
+	}
+	return c.w.Write([]string{strconv.Itoa(r.Index), r.Name, r.Locale, r.Greeting, r.Error})
+}
+
+func (c *csvWriter) Close() error {
+	if !c.header {
+		c.header = true
+		c.w.Write(recordHeader)
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/zhangbaodong/test"
+)
+
+// greeterFlags are the flags shared by commands that greet names.
+type greeterFlags struct {
+	locale    string
+	formal    bool
+	timeOfDay bool
+	timeZone  string
+	format    formatFlag
+	escape    encodingFlag
+}
+
+func addGreeterFlags(fs *flag.FlagSet) *greeterFlags {
+	f := &greeterFlags{format: formatText}
+	fs.StringVar(&f.locale, "locale", test.DefaultLocale, "BCP 47 `tag` of the greeting language")
+	fs.BoolVar(&f.formal, "formal", false, "use the formal greeting")
+	fs.BoolVar(&f.timeOfDay, "time-of-day", false, "greet according to the time of day")
+	fs.StringVar(&f.timeZone, "tz", "", "IANA time `zone` for -time-of-day (default local)")
+	fs.Var(&f.format, "format", "output `format`: text, json, ndjson or csv")
+	fs.Var(&f.escape, "escape", "escape text output for embedding as `kind`: raw, html, json, shell, csv or markdown")
+	return f
+}
+
+// greeter builds the greeter the flags describe. A bad locale or time zone
+// is reported as invalid input.
+func (f *greeterFlags) greeter() (*test.LocaleGreeter, error) {
+	var opts []test.Option
+	if f.formal {
+		opts = append(opts, test.WithFormality(test.Formal))
+	}
+	if f.timeOfDay {
+		opts = append(opts, test.WithTimeOfDay(nil))
+		if f.timeZone != "" {
+			opts = append(opts, test.WithTimeZone(f.timeZone))
+		}
+	} else if f.timeZone != "" {
+		return nil, usageErrorf("-tz requires -time-of-day")
+	}
+	if f.escape != encodingFlag(test.EncodingRaw) && f.format != formatText {
+		return nil, usageErrorf("-escape requires -format text")
+	}
+
+	g, err := test.NewGreeter(f.locale, opts...)
+	if err != nil {
+		return nil, invalidInput(err)
+	}
+	return g, nil
+}
+
+var sayCommand = &command{
+	name:    "say",
+	args:    "NAME...",
+	summary: "Greet the names given as arguments",
+	flags: func(fs *flag.FlagSet) runFunc {
+		gf := addGreeterFlags(fs)
+		return func(e *env, args []string) error {
+			if len(args) == 0 {
+				return usageErrorf("no name given")
+			}
+			g, err := gf.greeter()
+			if err != nil {
+				return err
+			}
+
+			out := newRecordWriter(gf.format, gf.escape, e.stdout, e.stderr, "argument")
+			failed := 0
+			for i, name := range args {
+				r := record{Index: i + 1, Name: name, Locale: g.Locale()}
+				greeting, err := g.SayHiStrict(name)
+				if err != nil {
+					r.Error = err.Error()
+					failed++
+				}
+				r.Greeting = greeting
+				if err := out.Write(r); err != nil {
+					return err
+				}
+			}
+			if err := out.Close(); err != nil {
+				return err
+			}
+			if failed > 0 {
+				return invalidInput(fmt.Errorf("%d of %d names rejected", failed, len(args)))
+			}
+			return nil
+		}
+	},
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/zhangbaodong/test"
+	"github.com/zhangbaodong/test/config"
+	"github.com/zhangbaodong/test/greethttp"
+)
+
+// reloadInterval is how often serve checks its configuration file for
+// changes. Tests shorten it.
+var reloadInterval = 5 * time.Second
+
+// serveFlagKeys maps serve's flags to the configuration keys they set.
+var serveFlagKeys = map[string]string{
+	"addr":         "server.addr",
+	"default-name": "greeting.default_name",
+	"locale":       "greeting.locale",
+	"metrics":      "server.metrics",
+	"access-log":   "log.access",
+}
+
+var serveCommand = &command{
+	name:    "serve",
+	summary: "Serve greetings over HTTP",
+	flags: func(fs *flag.FlagSet) runFunc {
+		configPath := fs.String("config", "", "configuration `file` (.yaml, .json or .toml), reloaded on SIGHUP or change")
+		fs.String("addr", ":8080", "`address` to listen on")
+		fs.String("default-name", greethttp.DefaultName, "`name` greeted when a request has none")
+		fs.String("locale", test.DefaultLocale, "BCP 47 `tag` of the greeting language")
+		fs.Bool("metrics", true, "serve Prometheus metrics at /metrics")
+		fs.Bool("access-log", true, "log requests to stderr as JSON lines, with names redacted")
+		return func(e *env, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("unexpected arguments %q", args)
+			}
+			// Flags override GREET_* variables, which override the file.
+			reloader, err := config.NewReloader(&config.Loader{
+				Path:      *configPath,
+				EnvPrefix: "GREET_",
+				Flags:     fs,
+				FlagKeys:  serveFlagKeys,
+			})
+			if err != nil {
+				return err
+			}
+			cfg := reloader.Current()
+
+			var cache *test.GreetingCache
+			var greeterOpts []test.Option
+			if cfg.Cache.MaxEntries > 0 {
+				cache = test.NewGreetingCache(test.CacheConfig{
+					MaxEntries: cfg.Cache.MaxEntries,
+					MaxBytes:   cfg.Cache.MaxBytes,
+					TTL:        cfg.Cache.TTL.D(),
+				})
+				greeterOpts = append(greeterOpts, test.WithCache(cache))
+			}
+			g, err := cfg.Greeting.Greeter(greeterOpts...)
+			if err != nil {
+				return invalidInput(err)
+			}
+			// The greeter is swapped whole when the configuration reloads.
+			var greeter atomic.Value // greeterHolder
+			greeter.Store(greeterHolder{g})
+
+			opts := []greethttp.Option{
+				greethttp.WithGreeter(test.GreetFunc(func(name string) (string, error) {
+					return greeter.Load().(greeterHolder).SayHiStrict(name)
+				})),
+				greethttp.WithDefaultName(cfg.Greeting.DefaultName),
+			}
+			if cfg.Server.Metrics {
+				metrics := greethttp.NewMetrics()
+				if cache != nil {
+					metrics.TrackCache("greetings", cache)
+				}
+				opts = append(opts, greethttp.WithMetrics(metrics))
+			}
+			var handler http.Handler = cacheControl(greethttp.New(opts...), reloader)
+			var limiter *greethttp.RateLimiter
+			if cfg.RateLimit.Enabled {
+				limiter = greethttp.RateLimiting(handler, rateLimitPolicy(&cfg.RateLimit),
+					greethttp.WithRateLimitKey(rateLimitKey(&cfg.RateLimit)))
+				handler = limiter
+			}
+			// Requests of no tenant get the main greeter.
+			tenants := &tenantSet{mux: greethttp.NewTenantMux(handler), opts: greeterOpts, settings: reloader, stderr: e.stderr}
+			tenants.load(cfg)
+			handler = tenants.mux
+			if cfg.Log.Access {
+				handler = greethttp.Logging(handler, greethttp.WithLogOutput(e.stderr),
+					greethttp.WithNameRedactor(nameRedactor(cfg.Log.Names)))
+			}
+
+			reloader.OnReload(func(cfg *config.Config) {
+				tenants.load(cfg)
+				g, err := cfg.Greeting.Greeter(greeterOpts...)
+				if err != nil {
+					fmt.Fprintf(e.stderr, "greet: reload: %v\n", err)
+					return
+				}
+				greeter.Store(greeterHolder{g})
+				if limiter != nil {
+					limiter.SetPolicy(rateLimitPolicy(&cfg.RateLimit))
+				}
+				fmt.Fprintf(e.stderr, "greet: reloaded configuration, greeting %s in %s\n",
+					cfg.Greeting.Strategy, cfg.Greeting.Locale)
+			})
+
+			lis, err := net.Listen("tcp", cfg.Server.Addr)
+			if err != nil {
+				return err
+			}
+			mux := http.NewServeMux()
+			mux.Handle("/", handler)
+			// Signals are handled by main and arrive through e.ctx.
+			srv := greethttp.NewServer(&http.Server{
+				Handler:      mux,
+				ReadTimeout:  cfg.Server.ReadTimeout.D(),
+				WriteTimeout: cfg.Server.WriteTimeout.D(),
+				IdleTimeout:  cfg.Server.IdleTimeout.D(),
+			}, greethttp.WithSignals(),
+				greethttp.WithDrainTimeout(cfg.Server.DrainTimeout.D()),
+				greethttp.WithDrainDelay(cfg.Server.DrainDelay.D()))
+			mux.Handle("/readyz", srv.Readiness())
+			mux.Handle("/livez", srv.Liveness())
+
+			go reloader.Watch(e.ctx, reloadInterval, func(err error) {
+				fmt.Fprintf(e.stderr, "greet: reload: %v\n", err)
+			})
+			fmt.Fprintf(e.stderr, "greet: serving %s %s greetings on http://%s\n",
+				cfg.Greeting.Strategy, cfg.Greeting.Locale, lis.Addr())
+			return srv.Serve(e.ctx, lis)
+		}
+	},
+}
+
+// greeterHolder lets an atomic.Value hold greeters of any concrete type.
+type greeterHolder struct {
+	test.Greeter
+}
+
+// cacheControl sets the Cache-Control of next's /api/ responses to the
+// configured max-age, which follows configuration reloads. It wraps the
+// greeting handler rather than the rate limiter so that clients never
+// cache being limited.
+func cacheControl(next http.Handler, settings *config.Reloader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			maxAge := int(settings.Current().Cache.MaxAge.D().Seconds())
+			w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func rateLimitPolicy(rl *config.RateLimit) greethttp.RateLimitPolicy {
+	return greethttp.RateLimitPolicy{Rate: rl.Rate, Burst: rl.Burst}
+}
+
+// rateLimitKey returns how clients are told apart for rate limiting.
+func rateLimitKey(rl *config.RateLimit) greethttp.KeyFunc {
+	if rl.KeyHeader != "" {
+		return greethttp.APIKeyOrIP(rl.KeyHeader, greethttp.APIKeySet(rl.APIKeys...))
+	}
+	return greethttp.ClientIP
+}
+
+// tenantSet keeps a TenantMux in step with the tenants directory. A tenant
+// whose profile stops loading keeps serving its last good one, and never
+// affects the others.
+type tenantSet struct {
+	mux      *greethttp.TenantMux
+	opts     []test.Option // applied to every tenant's greeter
+	settings *config.Reloader
+	stderr   io.Writer
+}
+
+// load serves the tenants in cfg's tenants directory, replacing every
+// tenant whose profile loads and removing those whose file is gone.
+// Reloaded tenants start with fresh rate limits.
+func (s *tenantSet) load(cfg *config.Config) {
+	var tenants []*config.Tenant
+	failed := make(map[string]error)
+	if cfg.Tenants.Dir != "" {
+		var err error
+		tenants, err = config.LoadTenants(cfg.Tenants.Dir, cfg)
+		var te *config.TenantError
+		if errors.As(err, &te) {
+			failed = te.Failed
+		} else if err != nil {
+			fmt.Fprintf(s.stderr, "greet: tenants: %v; keeping the current tenants\n", err)
+			return
+		}
+	}
+
+	for _, t := range tenants {
+		h, err := tenantHandler(t, s.opts, s.settings)
+		if err == nil {
+			err = s.mux.Handle(t.Name, greethttp.TenantRoutes{
+				APIKeys:  t.APIKeys,
+				Hosts:    t.Hosts,
+				Prefixes: t.Prefixes,
+			}, h)
+		}
+		if err != nil {
+			failed[t.Name] = err
+		}
+	}
+	keep := make(map[string]bool)
+	for _, t := range tenants {
+		keep[t.Name] = true
+	}
+	for _, name := range s.mux.Tenants() {
+		if err, ok := failed[name]; ok {
+			fmt.Fprintf(s.stderr, "greet: tenant %s: %v; serving its previous profile\n", name, err)
+			delete(failed, name)
+		} else if !keep[name] {
+			s.mux.Remove(name)
+		}
+	}
+	for name, err := range failed {
+		fmt.Fprintf(s.stderr, "greet: tenant %s: %v; not served\n", name, err)
+	}
+}
+
+// tenantHandler returns the handler serving tenant t, with the Cache-Control
+// of settings.
+func tenantHandler(t *config.Tenant, opts []test.Option, settings *config.Reloader) (http.Handler, error) {
+	g, err := t.Greeting.Greeter(opts...)
+	if err != nil {
+		return nil, err
+	}
+	h := cacheControl(greethttp.New(
+		greethttp.WithGreeter(g),
+		greethttp.WithDefaultName(t.Greeting.DefaultName),
+		greethttp.WithBranding(greethttp.Branding{
+			Title:      t.Branding.Title,
+			Accent:     t.Branding.Accent,
+			Stylesheet: t.Branding.Stylesheet,
+		})), settings)
+	if t.RateLimit.Enabled {
+		h = greethttp.RateLimiting(h, rateLimitPolicy(&t.RateLimit), greethttp.WithRateLimitKey(rateLimitKey(&t.RateLimit)))
+	}
+	return h, nil
+}
+
+// nameRedactor returns the access log redactor for a log.names setting.
+func nameRedactor(mode string) func(string) string {
+	switch mode {
+	case "keep":
+		return nil
+	case "hash":
+		return greethttp.HashName
+	}
+	return greethttp.RedactName
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer the server and the test can share
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// writeFiles writes files into a temporary directory and returns it
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// startServe runs greet serve in-process on a loopback port until the
+// test ends and returns its base URL
+func startServe(t *testing.T, args ...string) string {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	stderr := &syncBuffer{}
+	done := make(chan int, 1)
+	e := &env{ctx: ctx, stdin: strings.NewReader(""), stdout: io.Discard, stderr: stderr}
+	go func() {
+		done <- run(e, append([]string{"serve", "-addr", "127.0.0.1:0", "-access-log=false"}, args...))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, url, ok := strings.Cut(stderr.String(), " on "); ok {
+			return strings.TrimSpace(url)
+		}
+		select {
+		case code := <-done:
+			t.Fatalf("serve exited with %d: %s", code, stderr.String())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Fatalf("serve did not start: %s", stderr.String())
+	return ""
+}
+
+// fetch returns the body served at url
+func fetch(t *testing.T, url string) string {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// TestServeTenantsShareNoGreetings tests that tenants with their own
+// catalogs never get each other's cached greetings
+func TestServeTenantsShareNoGreetings(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.yaml":             "prefixes: [/a]\ngreeting:\n  catalog: a-messages\n",
+		"a-messages/en.json": `{"greeting": "Hello{punct}{name}", "punct": ", "}`,
+		"b.yaml":             "prefixes: [/b]\ngreeting:\n  catalog: b-messages\n",
+		"b-messages/en.json": `{"greeting": "Hello{punct}{name}", "punct": " dear "}`,
+	})
+	config := writeFiles(t, map[string]string{"greet.yaml": "tenants:\n  dir: " + strconv.Quote(dir) + "\n"})
+
+	url := startServe(t, "-config", filepath.Join(config, "greet.yaml"))
+	for i := 0; i < 2; i++ {
+		if got := fetch(t, url+"/a/api/simple?name=Ann"); got != "Hello, Ann" {
+			t.Errorf("tenant a greeted %q, want %q", got, "Hello, Ann")
+		}
+		if got := fetch(t, url+"/b/api/simple?name=Ann"); got != "Hello dear Ann" {
+			t.Errorf("tenant b greeted %q, want %q", got, "Hello dear Ann")
+		}
+	}
+}
+
+// TestServeCacheControl tests that API responses carry the configured
+// max-age, following reloads, while pages and limited requests do not
+func TestServeCacheControl(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.yaml": "prefixes: [/a]\nrate_limit:\n  enabled: true\n  rate: 0.001\n  burst: 1\n",
+	})
+	settings := func(maxAge string) string {
+		return "cache:\n  max_age: " + maxAge + "\ntenants:\n  dir: " + strconv.Quote(dir) + "\n"
+	}
+	path := filepath.Join(writeFiles(t, map[string]string{"greet.yaml": settings("30s")}), "greet.yaml")
+	interval := reloadInterval
+	reloadInterval = 10 * time.Millisecond
+	t.Cleanup(func() { reloadInterval = interval })
+
+	url := startServe(t, "-config", path)
+	cacheControl := func(path string) (int, string) {
+		t.Helper()
+		resp, err := http.Get(url + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode, resp.Header.Get("Cache-Control")
+	}
+
+	tests := []struct {
+		path     string
+		status   int
+		expected string
+	}{
+		{"/api/simple?name=Ann", http.StatusOK, "public, max-age=30"},
+		{"/", http.StatusOK, ""},
+		{"/a/api/simple?name=Ann", http.StatusOK, "public, max-age=30"},
+		{"/a/api/simple?name=Ann", http.StatusTooManyRequests, ""},
+	}
+	for _, tt := range tests {
+		if status, got := cacheControl(tt.path); status != tt.status || got != tt.expected {
+			t.Errorf("GET %s = %d with Cache-Control %q, want %d with %q", tt.path, status, got, tt.status, tt.expected)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(settings("2m")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, got := cacheControl("/api/simple?name=Ann")
+		if got == "public, max-age=120" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Cache-Control after reload = %q, want %q", got, "public, max-age=120")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Build metadata, injected by the linker:
+//
+//	go build -ldflags "-X main.version=v1.2.0 -X main.commit=$(git rev-parse HEAD) -X main.date=$(date -u +%FT%TZ)" ./cmd/greet
+//
+// Values left empty are filled in from the module and VCS information Go
+// embeds in the binary, where available.
+var (
+	version string
+	commit  string
+	date    string
+)
+
+// buildInfo returns the version, commit and build date of the binary.
+func buildInfo() (v, c, d string) {
+	v, c, d = version, commit, date
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if v == "" && info.Main.Version != "" {
+			v = info.Main.Version
+		}
+		for _, s := range info.Settings {
+			switch {
+			case s.Key == "vcs.revision" && c == "":
+				c = s.Value
+			case s.Key == "vcs.time" && d == "":
+				d = s.Value
+			}
+		}
+	}
+	if v == "" {
+		v = "(devel)"
+	}
+	if len(c) > 12 {
+		c = c[:12]
+	}
+	return v, c, d
+}
+
+var versionCommand = &command{
+	name:    "version",
+	summary: "Print version information",
+	flags: func(fs *flag.FlagSet) runFunc {
+		return func(e *env, args []string) error {
+			v, c, d := buildInfo()
+			fmt.Fprintf(e.stdout, "greet %s\n", v)
+			if c != "" {
+				fmt.Fprintf(e.stdout, "commit: %s\n", c)
+			}
+			if d != "" {
+				fmt.Fprintf(e.stdout, "built: %s\n", d)
+			}
+			fmt.Fprintf(e.stdout, "go: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+			return nil
+		}
+	},
+}
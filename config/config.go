@@ -0,0 +1,308 @@
+// Package config loads the settings of the greeting server and CLI from a
+// YAML, JSON or TOML file, environment variables and command-line flags,
+// and reloads them while the server runs.
+//
+// Sources are applied in order of increasing precedence:
+//
+//  1. the defaults returned by Default;
+//  2. the file, whose format is chosen by its extension;
+//  3. environment variables such as GREET_SERVER_ADDR;
+//  4. flags set explicitly on the command line.
+//
+// Every setting has a dotted key, such as "server.addr", formed from the
+// file's section and field names. Its environment variable is the key in
+// upper case with dots replaced by underscores, after the loader's prefix;
+// flags registered by RegisterFlags are named after the key itself.
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zhangbaodong/test"
+)
+
+// Config holds every setting. Fields tagged reload:"true" can change while
+// the server runs; the rest take effect on restart. See Reloader.
+type Config struct {
+	Server    Server    `json:"server" yaml:"server" toml:"server"`
+	Greeting  Greeting  `json:"greeting" yaml:"greeting" toml:"greeting"`
+	Cache     Cache     `json:"cache" yaml:"cache" toml:"cache"`
+	RateLimit RateLimit `json:"rate_limit" yaml:"rate_limit" toml:"rate_limit"`
+	Log       Log       `json:"log" yaml:"log" toml:"log"`
+	Tenants   Tenants   `json:"tenants" yaml:"tenants" toml:"tenants"`
+}
+
+// Server configures the HTTP server.
+type Server struct {
+	Addr         string   `json:"addr" yaml:"addr" toml:"addr" usage:"address to listen on"`
+	ReadTimeout  Duration `json:"read_timeout" yaml:"read_timeout" toml:"read_timeout" usage:"maximum duration for reading a request"`
+	WriteTimeout Duration `json:"write_timeout" yaml:"write_timeout" toml:"write_timeout" usage:"maximum duration for writing a response"`
+	IdleTimeout  Duration `json:"idle_timeout" yaml:"idle_timeout" toml:"idle_timeout" usage:"how long idle keep-alive connections stay open"`
+	DrainTimeout Duration `json:"drain_timeout" yaml:"drain_timeout" toml:"drain_timeout" usage:"how long shutdown waits for in-flight requests"`
+	DrainDelay   Duration `json:"drain_delay" yaml:"drain_delay" toml:"drain_delay" usage:"how long to keep serving after readiness fails on shutdown"`
+	Metrics      bool     `json:"metrics" yaml:"metrics" toml:"metrics" usage:"serve Prometheus metrics at /metrics"`
+}
+
+// Greeting configures the greeter.
+type Greeting struct {
+	Strategy    string `json:"strategy" yaml:"strategy" toml:"strategy" reload:"true" usage:"registered greeting strategy, e.g. localized, formal or time-of-day"`
+	Locale      string `json:"locale" yaml:"locale" toml:"locale" reload:"true" usage:"BCP 47 tag of the greeting language"`
+	Formal      bool   `json:"formal" yaml:"formal" toml:"formal" reload:"true" usage:"use the formal greeting"`
+	DefaultName string `json:"default_name" yaml:"default_name" toml:"default_name" usage:"name greeted when a request has none"`
+	Catalog     string `json:"catalog" yaml:"catalog" toml:"catalog" reload:"true" usage:"directory of <tag>.json message files loaded over the built-in locales"`
+
+	// Templates overrides the greeting template per locale, e.g.
+	// {"en": "Hello, {name}!"}. It is only settable in the file.
+	Templates map[string]string `json:"templates" yaml:"templates" toml:"templates" reload:"true"`
+}
+
+// Cache configures the greeting cache and HTTP caching.
+type Cache struct {
+	MaxEntries int      `json:"max_entries" yaml:"max_entries" toml:"max_entries" usage:"most greetings cached; 0 disables the cache"`
+	MaxBytes   int      `json:"max_bytes" yaml:"max_bytes" toml:"max_bytes" usage:"approximate memory bound of the cache; 0 means none"`
+	TTL        Duration `json:"ttl" yaml:"ttl" toml:"ttl" usage:"how long a cached greeting stays valid; 0 means forever"`
+	MaxAge     Duration `json:"max_age" yaml:"max_age" toml:"max_age" reload:"true" usage:"Cache-Control max-age of API responses"`
+}
+
+// RateLimit configures per-client rate limiting.
+type RateLimit struct {
+	Enabled   bool    `json:"enabled" yaml:"enabled" toml:"enabled" usage:"rate limit greeting requests"`
+	Rate      float64 `json:"rate" yaml:"rate" toml:"rate" reload:"true" usage:"requests per second regained by each client"`
+	Burst     int     `json:"burst" yaml:"burst" toml:"burst" reload:"true" usage:"requests a client may make at once"`
+	KeyHeader string  `json:"key_header" yaml:"key_header" toml:"key_header" usage:"header identifying clients by API key instead of IP"`
+
+	// APIKeys are the keys KeyHeader may carry. Requests with other keys
+	// are limited by IP, so made-up keys cannot dodge the limit. They are
+	// only settable in the file.
+	APIKeys []string `json:"api_keys" yaml:"api_keys" toml:"api_keys"`
+}
+
+// Log configures request logging.
+type Log struct {
+	Access bool   `json:"access" yaml:"access" toml:"access" usage:"log requests as JSON lines"`
+	Names  string `json:"names" yaml:"names" toml:"names" usage:"how access logs show names: keep, redact or hash"`
+}
+
+// Tenants configures the tenant profiles served besides the main greeter.
+type Tenants struct {
+	Dir string `json:"dir" yaml:"dir" toml:"dir" reload:"true" usage:"directory of <name>.yaml, .json or .toml tenant profiles; see LoadTenants"`
+}
+
+// Default returns the built-in settings, which match the values the
+// examples used to hardcode.
+func Default() Config {
+	return Config{
+		Server: Server{
+			Addr:         ":8080",
+			ReadTimeout:  Duration(15 * time.Second),
+			WriteTimeout: Duration(15 * time.Second),
+			IdleTimeout:  Duration(60 * time.Second),
+			DrainTimeout: Duration(30 * time.Second),
+			Metrics:      true,
+		},
+		Greeting: Greeting{
+			Strategy:    test.StrategyLocalized,
+			Locale:      test.DefaultLocale,
+			DefaultName: "Guest",
+		},
+		Cache: Cache{
+			MaxEntries: 10000,
+			TTL:        Duration(time.Hour),
+			MaxAge:     Duration(time.Hour),
+		},
+		RateLimit: RateLimit{
+			Rate:  5,
+			Burst: 20,
+		},
+		Log: Log{
+			Access: true,
+			Names:  "redact",
+		},
+	}
+}
+
+// Duration is a time.Duration written as a string such as "15s" or "1h30m"
+// in files, environment variables and flags.
+type Duration time.Duration
+
+// D returns d as a time.Duration.
+func (d Duration) D() time.Duration {
+	return time.Duration(d)
+}
+
+// String returns d formatted like time.Duration.
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Greeter returns the greeter the settings describe, built by the
+// strategy registered under g.Strategy with opts such as test.WithCache
+// applied after the settings.
+func (g *Greeting) Greeter(opts ...test.Option) (test.Greeter, error) {
+	c := test.DefaultCatalog()
+	if g.Catalog != "" {
+		if err := c.LoadDir(g.Catalog); err != nil {
+			return nil, err
+		}
+	}
+	for tag, tpl := range g.Templates {
+		if err := c.Set(tag, test.MessageGreeting, tpl); err != nil {
+			return nil, err
+		}
+	}
+	all := []test.Option{test.WithCatalog(c)}
+	if g.Formal {
+		all = append(all, test.WithFormality(test.Formal))
+	}
+	return test.NewStrategy(g.Strategy, g.Locale, append(all, opts...)...)
+}
+
+// ValidationError reports every invalid setting of a Config.
+type ValidationError struct {
+	Problems []string // one per setting, e.g. "server.addr: missing port"
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks the settings, reporting all problems at once as a
+// *ValidationError. It builds the greeter to check the strategy, locale,
+// catalog and templates.
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(key, format string, args ...interface{}) {
+		problems = append(problems, key+": "+fmt.Sprintf(format, args...))
+	}
+
+	if _, port, err := net.SplitHostPort(c.Server.Addr); err != nil {
+		add("server.addr", "%v", addrError(err))
+	} else if n, err := strconv.Atoi(port); err == nil {
+		if n < 0 || n > 65535 {
+			add("server.addr", "port %d out of range", n)
+		}
+	} else if _, err := net.LookupPort("tcp", port); err != nil {
+		add("server.addr", "unknown port %q", port)
+	}
+	durations := []struct {
+		key string
+		d   Duration
+	}{
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.drain_delay", c.Server.DrainDelay},
+		{"cache.ttl", c.Cache.TTL},
+		{"cache.max_age", c.Cache.MaxAge},
+	}
+	for _, d := range durations {
+		if d.d < 0 {
+			add(d.key, "must not be negative, got %v", d.d)
+		}
+	}
+	if c.Server.DrainTimeout <= 0 {
+		add("server.drain_timeout", "must be positive, got %v", c.Server.DrainTimeout)
+	}
+
+	validateGreeting(&c.Greeting, "greeting", add)
+	if err := test.ValidateName(c.Greeting.DefaultName); err != nil {
+		add("greeting.default_name", "%v", err)
+	}
+
+	if c.Cache.MaxEntries < 0 {
+		add("cache.max_entries", "must not be negative, got %d", c.Cache.MaxEntries)
+	}
+	if c.Cache.MaxBytes < 0 {
+		add("cache.max_bytes", "must not be negative, got %d", c.Cache.MaxBytes)
+	}
+
+	validateRateLimit(&c.RateLimit, "rate_limit", add)
+
+	switch c.Log.Names {
+	case "keep", "redact", "hash":
+	default:
+		add("log.names", "must be keep, redact or hash, got %q", c.Log.Names)
+	}
+
+	if c.Tenants.Dir != "" {
+		validateDir(c.Tenants.Dir, "tenants.dir", add)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// validateGreeting reports the problems of the greeting settings in
+// section, building the greeter to check the strategy, locale, catalog and
+// templates.
+func validateGreeting(g *Greeting, section string, add func(key, format string, args ...interface{})) {
+	if g.Catalog != "" && !validateDir(g.Catalog, section+".catalog", add) {
+		return
+	}
+	if _, err := g.Greeter(); err != nil {
+		add(section, "%v", err)
+	}
+}
+
+// validateRateLimit reports the problems of the rate limit settings in
+// section, if limiting is enabled.
+func validateRateLimit(rl *RateLimit, section string, add func(key, format string, args ...interface{})) {
+	if !rl.Enabled {
+		return
+	}
+	if !(rl.Rate > 0) {
+		add(section+".rate", "must be positive, got %v", rl.Rate)
+	}
+	if rl.Burst < 1 {
+		add(section+".burst", "must be at least 1, got %d", rl.Burst)
+	}
+	if rl.KeyHeader != "" && len(rl.APIKeys) == 0 {
+		add(section+".api_keys", "must list the keys %s may carry", rl.KeyHeader)
+	}
+}
+
+// validateDir reports whether dir is a directory, adding a problem for key
+// if not.
+func validateDir(dir, key string, add func(key, format string, args ...interface{})) bool {
+	fi, err := os.Stat(dir)
+	if err != nil {
+		add(key, "%v", err)
+		return false
+	}
+	if !fi.IsDir() {
+		add(key, "%s is not a directory", dir)
+		return false
+	}
+	return true
+}
+
+// addrError drops the address net.SplitHostPort repeats in its errors,
+// since the key already identifies the setting.
+func addrError(err error) string {
+	if ae, ok := err.(*net.AddrError); ok {
+		return ae.Err
+	}
+	return err.Error()
+}
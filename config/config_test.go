@@ -0,0 +1,104 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDefault tests that the defaults are valid and match the old examples
+func TestDefault(t *testing.T) {
+	c := Default()
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Default().Validate() = %v", err)
+	}
+	if c.Server.Addr != ":8080" || c.Greeting.DefaultName != "Guest" || c.Cache.MaxAge.D() != time.Hour {
+		t.Errorf("Default() = %+v", c)
+	}
+}
+
+// TestValidate tests that every invalid setting is reported
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(c *Config)
+		problems []string
+	}{
+		{"valid port name", func(c *Config) { c.Server.Addr = "localhost:http" }, nil},
+		{"missing port", func(c *Config) { c.Server.Addr = "8080" }, []string{"server.addr: missing port in address"}},
+		{"port range", func(c *Config) { c.Server.Addr = ":70000" }, []string{"server.addr: port 70000 out of range"}},
+		{"unknown port", func(c *Config) { c.Server.Addr = ":nope" }, []string{`server.addr: unknown port "nope"`}},
+		{"negative timeout", func(c *Config) { c.Server.ReadTimeout = Duration(-time.Second) }, []string{"server.read_timeout: must not be negative, got -1s"}},
+		{"zero drain", func(c *Config) { c.Server.DrainTimeout = 0 }, []string{"server.drain_timeout: must be positive, got 0s"}},
+		{"locale", func(c *Config) { c.Greeting.Locale = "not a tag" }, []string{"greeting: "}},
+		{"strategy", func(c *Config) { c.Greeting.Strategy = "shouting" }, []string{`greeting: unknown greeting strategy "shouting"`}},
+		{"template", func(c *Config) { c.Greeting.Templates = map[string]string{"en": "Hi, {nmae}"} }, []string{"greeting: "}},
+		{"default name", func(c *Config) { c.Greeting.DefaultName = "" }, []string{"greeting.default_name: "}},
+		{"missing catalog", func(c *Config) { c.Greeting.Catalog = filepath.Join(t.TempDir(), "none") }, []string{"greeting.catalog: "}},
+		{"cache", func(c *Config) { c.Cache.MaxEntries = -1; c.Cache.TTL = Duration(-1) }, []string{"cache.ttl: ", "cache.max_entries: "}},
+		{"rate limit off", func(c *Config) { c.RateLimit.Rate = 0 }, nil},
+		{"rate limit", func(c *Config) { c.RateLimit = RateLimit{Enabled: true} }, []string{"rate_limit.rate: ", "rate_limit.burst: "}},
+		{"api keys", func(c *Config) { c.RateLimit = RateLimit{Enabled: true, Rate: 1, Burst: 1, KeyHeader: "X-API-Key"} }, []string{"rate_limit.api_keys: "}},
+		{"log names", func(c *Config) { c.Log.Names = "mask" }, []string{`log.names: must be keep, redact or hash, got "mask"`}},
+	}
+
+	for _, tt := range tests {
+		c := Default()
+		tt.modify(&c)
+		err := c.Validate()
+		if tt.problems == nil {
+			if err != nil {
+				t.Errorf("%s: Validate() = %v", tt.name, err)
+			}
+			continue
+		}
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("%s: Validate() = %v, want *ValidationError", tt.name, err)
+			continue
+		}
+		if len(verr.Problems) != len(tt.problems) {
+			t.Errorf("%s: problems = %q, want %d", tt.name, verr.Problems, len(tt.problems))
+			continue
+		}
+		for i, p := range tt.problems {
+			if !strings.HasPrefix(verr.Problems[i], p) {
+				t.Errorf("%s: problem %d = %q, want prefix %q", tt.name, i, verr.Problems[i], p)
+			}
+		}
+	}
+}
+
+// TestGreetingGreeter tests building the greeter from the settings
+func TestGreetingGreeter(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "de.json"), []byte(`{"greeting": "Servus, {name}"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		greeting Greeting
+		expected string
+	}{
+		{Greeting{Strategy: "localized", Locale: "en"}, "Hi, Alice"},
+		{Greeting{Strategy: "localized", Locale: "de", Catalog: dir}, "Servus, Alice"},
+		{Greeting{Strategy: "localized", Locale: "en", Templates: map[string]string{"en": "Hello, {name}!"}}, "Hello, Alice!"},
+		{Greeting{Strategy: "localized", Locale: "fr", Templates: map[string]string{"en": "Hello, {name}!"}}, "Salut, Alice"},
+		{Greeting{Strategy: "formal", Locale: "fr"}, "Bonjour, Alice"},
+		{Greeting{Strategy: "classic", Locale: "fr"}, "Hi, Alice"},
+	}
+
+	for _, tt := range tests {
+		g, err := tt.greeting.Greeter()
+		if err != nil {
+			t.Errorf("%+v: %v", tt.greeting, err)
+			continue
+		}
+		if result, _ := g.SayHiStrict("Alice"); result != tt.expected {
+			t.Errorf("%+v: SayHi = %q, want %q", tt.greeting, result, tt.expected)
+		}
+	}
+}
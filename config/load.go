@@ -0,0 +1,288 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Loader loads a Config from its sources. The zero Loader returns the
+// defaults.
+//
+// Example:
+//
+//	fs := flag.NewFlagSet("greet", flag.ExitOnError)
+//	path := fs.String("config", "", "configuration `file`")
+//	config.RegisterFlags(fs)
+//	fs.Parse(os.Args[1:])
+//
+//	l := &config.Loader{Path: *path, EnvPrefix: "GREET_", Flags: fs}
+//	cfg, err := l.Load()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+type Loader struct {
+	// Path is the configuration file; empty means none. Its extension
+	// selects the format: .yaml or .yml, .json, or .toml. Unknown
+	// settings in the file are errors, so typos do not go unnoticed.
+	Path string
+
+	// EnvPrefix prefixes the environment variables consulted, e.g.
+	// "GREET_" for GREET_SERVER_ADDR. Empty disables the environment.
+	EnvPrefix string
+
+	// Flags, if non-nil, is a parsed flag set whose explicitly set flags
+	// override the other sources. A flag sets the key it is named after,
+	// as registered by RegisterFlags, or the key FlagKeys maps it to;
+	// other flags are ignored.
+	Flags *flag.FlagSet
+
+	// FlagKeys maps flag names to keys, for flags not named after their
+	// key, e.g. {"addr": "server.addr"}.
+	FlagKeys map[string]string
+
+	// LookupEnv reads the environment; nil means os.LookupEnv.
+	LookupEnv func(name string) (string, bool)
+}
+
+// Load returns the configuration with every source applied, or the first
+// error reading a source. The result is validated; invalid settings are
+// reported together as a *ValidationError.
+func (l *Loader) Load() (*Config, error) {
+	c := Default()
+	if l.Path != "" {
+		if err := decodeFile(&c, l.Path); err != nil {
+			return nil, err
+		}
+	}
+	if err := l.applyEnv(&c); err != nil {
+		return nil, err
+	}
+	if err := l.applyFlags(&c); err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// decodeFile decodes the file at path over c, a *Config or *Tenant,
+// keeping the settings it does not mention.
+func decodeFile(c interface{}, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(c)
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err = dec.Decode(c); err == io.EOF {
+			err = nil // an empty file changes nothing
+		}
+	case ".toml":
+		var md toml.MetaData
+		md, err = toml.Decode(string(data), c)
+		if undecoded := md.Undecoded(); err == nil && len(undecoded) > 0 {
+			err = fmt.Errorf("unknown setting %q", undecoded[0].String())
+		}
+	default:
+		return fmt.Errorf("config: %s: unknown format %q, want .yaml, .yml, .json or .toml", path, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+	return nil
+}
+
+func (l *Loader) applyEnv(c *Config) error {
+	if l.EnvPrefix == "" {
+		return nil
+	}
+	lookup := l.LookupEnv
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	for _, s := range settings(c) {
+		if !s.scalar() {
+			continue
+		}
+		name := l.EnvPrefix + strings.ToUpper(strings.ReplaceAll(s.key, ".", "_"))
+		if text, ok := lookup(name); ok {
+			if err := s.set(text); err != nil {
+				return fmt.Errorf("config: $%s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (l *Loader) applyFlags(c *Config) error {
+	if l.Flags == nil {
+		return nil
+	}
+	byKey := make(map[string]setting)
+	for _, s := range settings(c) {
+		if s.scalar() {
+			byKey[s.key] = s
+		}
+	}
+
+	var err error
+	l.Flags.Visit(func(f *flag.Flag) {
+		key := f.Name
+		if k, ok := l.FlagKeys[f.Name]; ok {
+			key = k
+		}
+		s, ok := byKey[key]
+		if !ok || err != nil {
+			return
+		}
+		if e := s.set(f.Value.String()); e != nil {
+			err = fmt.Errorf("config: -%s: %w", f.Name, e)
+		}
+	})
+	return err
+}
+
+// RegisterFlags defines a flag on fs for every setting that can be given
+// as text, named after its key, e.g. -server.addr or -rate_limit.burst.
+// Their help shows the defaults; only flags set on the command line
+// override the other sources.
+func RegisterFlags(fs *flag.FlagSet) {
+	def := Default()
+	for _, s := range settings(&def) {
+		if s.scalar() {
+			fs.Var(&flagValue{text: s.String(), s: s}, s.key, s.usage)
+		}
+	}
+}
+
+// flagValue is a flag.Value that checks its text against a setting of a
+// scratch Config. Loader.applyFlags applies the text to the real one.
+type flagValue struct {
+	text string
+	s    setting
+}
+
+func (f *flagValue) String() string {
+	return f.text
+}
+
+func (f *flagValue) Set(text string) error {
+	if err := f.s.set(text); err != nil {
+		return err
+	}
+	f.text = text
+	return nil
+}
+
+func (f *flagValue) IsBoolFlag() bool {
+	return f.s.value.IsValid() && f.s.value.Kind() == reflect.Bool
+}
+
+// setting is one field of a Config, addressable through value.
+type setting struct {
+	key    string // e.g. "server.addr"
+	usage  string
+	reload bool // may change while the server runs
+	value  reflect.Value
+}
+
+var durationType = reflect.TypeOf(Duration(0))
+
+// settings returns the fields of c in declaration order.
+func settings(c *Config) []setting {
+	var out []setting
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		section := v.Field(i)
+		prefix := fieldName(v.Type().Field(i)) + "."
+		for j := 0; j < section.NumField(); j++ {
+			f := section.Type().Field(j)
+			out = append(out, setting{
+				key:    prefix + fieldName(f),
+				usage:  f.Tag.Get("usage"),
+				reload: f.Tag.Get("reload") == "true",
+				value:  section.Field(j),
+			})
+		}
+	}
+	return out
+}
+
+// fieldName returns the name of a field in configuration files.
+func fieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	return name
+}
+
+// scalar reports whether the setting can be given as text, in the
+// environment or a flag.
+func (s setting) scalar() bool {
+	k := s.value.Kind()
+	return k != reflect.Map && k != reflect.Slice
+}
+
+// String formats the setting's value the way set parses it.
+func (s setting) String() string {
+	return fmt.Sprint(s.value.Interface())
+}
+
+// set parses text into a scalar setting.
+func (s setting) set(text string) error {
+	v := s.value
+	if v.Type() == durationType {
+		var d Duration
+		if err := d.UnmarshalText([]byte(text)); err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(d))
+		return nil
+	}
+
+	var err error
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(text); err == nil {
+			v.SetBool(b)
+		}
+	case reflect.Int:
+		var n int64
+		if n, err = strconv.ParseInt(text, 10, 0); err == nil {
+			v.SetInt(n)
+		}
+	case reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(text, 64); err == nil {
+			v.SetFloat(f)
+		}
+	default:
+		return fmt.Errorf("%s cannot be set from text", s.key)
+	}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return fmt.Errorf("invalid value %q for %s: %w", text, s.key, numErr.Err)
+	}
+	return err
+}
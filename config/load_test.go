@@ -0,0 +1,202 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile writes a configuration file into a temporary directory
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// env returns a LookupEnv reading from vars
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+// TestLoadFormats tests that the file formats decode the same settings
+func TestLoadFormats(t *testing.T) {
+	files := map[string]string{
+		"greet.yaml": `
+server:
+  addr: ":9090"
+  read_timeout: 5s
+greeting:
+  locale: de
+  templates:
+    de: "Moin, {name}"
+rate_limit:
+  enabled: true
+  rate: 0.5
+`,
+		"greet.json": `{
+  "server": {"addr": ":9090", "read_timeout": "5s"},
+  "greeting": {"locale": "de", "templates": {"de": "Moin, {name}"}},
+  "rate_limit": {"enabled": true, "rate": 0.5}
+}`,
+		"greet.toml": `
+[server]
+addr = ":9090"
+read_timeout = "5s"
+
+[greeting]
+locale = "de"
+templates = { de = "Moin, {name}" }
+
+[rate_limit]
+enabled = true
+rate = 0.5
+`,
+	}
+
+	for name, content := range files {
+		c, err := (&Loader{Path: writeFile(t, name, content)}).Load()
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if c.Server.Addr != ":9090" || c.Server.ReadTimeout.D() != 5*time.Second ||
+			c.Greeting.Locale != "de" || c.Greeting.Templates["de"] != "Moin, {name}" ||
+			!c.RateLimit.Enabled || c.RateLimit.Rate != 0.5 {
+			t.Errorf("%s: loaded %+v", name, c)
+		}
+		// Settings the file does not mention keep their defaults.
+		if c.Server.WriteTimeout.D() != 15*time.Second || c.RateLimit.Burst != 20 {
+			t.Errorf("%s: defaults lost: %+v", name, c)
+		}
+	}
+}
+
+// TestLoadFileErrors tests that bad files are reported with their path
+func TestLoadFileErrors(t *testing.T) {
+	tests := []struct {
+		name, content string
+		expected      string
+	}{
+		{"typo.yaml", "server:\n  adr: \":80\"\n", "field adr not found"},
+		{"typo.json", `{"server": {"adr": ":80"}}`, `unknown field "adr"`},
+		{"typo.toml", "[server]\nadr = \":80\"\n", `unknown setting "server.adr"`},
+		{"duration.yaml", "server:\n  read_timeout: soon\n", `invalid duration "soon"`},
+		{"syntax.json", `{"server": `, "unexpected EOF"},
+		{"greet.ini", "addr=:80", `unknown format ".ini"`},
+		{"invalid.yaml", "server:\n  addr: \"80\"\n", "server.addr: missing port"},
+	}
+
+	for _, tt := range tests {
+		path := writeFile(t, tt.name, tt.content)
+		_, err := (&Loader{Path: path}).Load()
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%s: Load() = %v, want error containing %q", tt.name, err, tt.expected)
+		}
+	}
+
+	if _, err := (&Loader{Path: filepath.Join(t.TempDir(), "none.yaml")}).Load(); !os.IsNotExist(unwrapAll(err)) {
+		t.Errorf("missing file: Load() = %v", err)
+	}
+	if c, err := (&Loader{Path: writeFile(t, "empty.yaml", "")}).Load(); err != nil || c.Server.Addr != ":8080" {
+		t.Errorf("empty file: Load() = %+v, %v", c, err)
+	}
+}
+
+func unwrapAll(err error) error {
+	for {
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return err
+		}
+		err = u.Unwrap()
+	}
+}
+
+// TestLoadPrecedence tests defaults < file < environment < flags
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, "greet.yaml", `
+server:
+  addr: ":1000"
+  idle_timeout: 1m
+greeting:
+  locale: fr
+  default_name: File
+cache:
+  max_entries: 10
+`)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	RegisterFlags(fs)
+	fs.String("addr", "", "")
+	fs.String("unrelated", "", "")
+	if err := fs.Parse([]string{"-addr", ":3000", "-greeting.formal", "-unrelated", "x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	l := &Loader{
+		Path:      path,
+		EnvPrefix: "GREET_",
+		Flags:     fs,
+		FlagKeys:  map[string]string{"addr": "server.addr"},
+		LookupEnv: env(map[string]string{
+			"GREET_SERVER_ADDR":           ":2000",
+			"GREET_GREETING_DEFAULT_NAME": "Env",
+			"GREET_CACHE_TTL":             "5m",
+			"OTHER_CACHE_TTL":             "1s",
+		}),
+	}
+	c, err := l.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key      string
+		result   interface{}
+		expected interface{}
+	}{
+		{"server.addr", c.Server.Addr, ":3000"},
+		{"server.idle_timeout", c.Server.IdleTimeout.D(), time.Minute},
+		{"server.read_timeout", c.Server.ReadTimeout.D(), 15 * time.Second},
+		{"greeting.locale", c.Greeting.Locale, "fr"},
+		{"greeting.default_name", c.Greeting.DefaultName, "Env"},
+		{"greeting.formal", c.Greeting.Formal, true},
+		{"cache.max_entries", c.Cache.MaxEntries, 10},
+		{"cache.ttl", c.Cache.TTL.D(), 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if tt.result != tt.expected {
+			t.Errorf("%s = %v, want %v", tt.key, tt.result, tt.expected)
+		}
+	}
+}
+
+// TestLoadTextErrors tests bad values in the environment and flags
+func TestLoadTextErrors(t *testing.T) {
+	l := &Loader{EnvPrefix: "GREET_", LookupEnv: env(map[string]string{"GREET_CACHE_MAX_ENTRIES": "lots"})}
+	if _, err := l.Load(); err == nil || err.Error() != `config: $GREET_CACHE_MAX_ENTRIES: invalid value "lots" for cache.max_entries: invalid syntax` {
+		t.Errorf("env: Load() = %v", err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	RegisterFlags(fs)
+	if err := fs.Parse([]string{"-rate_limit.rate", "fast"}); err == nil || !strings.Contains(err.Error(), "invalid syntax") {
+		t.Errorf("flag: Parse() = %v", err)
+	}
+	if err := fs.Parse([]string{"-server.write_timeout", "1h"}); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := (&Loader{Flags: fs}).Load(); err != nil || c.Server.WriteTimeout.D() != time.Hour {
+		t.Errorf("flag: Load() = %+v, %v", c, err)
+	}
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ErrRestartRequired is wrapped by the error Reload returns when settings
+// that cannot change while the server runs, such as server.addr, differ
+// from the running configuration. Those settings keep their old values
+// until restart; the rest of the reload still applies.
+var ErrRestartRequired = errors.New("restart required")
+
+// Reloader holds the configuration of a running server and reloads it from
+// its Loader on SIGHUP or when the file changes. Only settings tagged
+// reload:"true" in Config change on reload: the greeting strategy, locale,
+// formality, catalog and templates, the rate limits, the cache max-age and
+// the tenants directory. OnReload functions may reread tenant profiles.
+//
+// Example:
+//
+//	r, err := config.NewReloader(&config.Loader{Path: "greet.yaml", EnvPrefix: "GREET_"})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	r.OnReload(func(cfg *config.Config) {
+//		limiter.SetPolicy(greethttp.RateLimitPolicy{Rate: cfg.RateLimit.Rate, Burst: cfg.RateLimit.Burst})
+//	})
+//	go r.Watch(ctx, 5*time.Second, func(err error) { log.Print(err) })
+//
+// Thread Safety:
+//   Current, Reload and Watch are safe for concurrent use. OnReload must be
+//   called before reloading starts.
+type Reloader struct {
+	loader  *Loader
+	current atomic.Value // *Config
+	hooks   []func(*Config)
+
+	mu   sync.Mutex // serializes reloads
+	stat fileStat   // of the file when it was last loaded
+}
+
+// fileStat is what Watch compares to notice a changed file.
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewReloader loads the initial configuration from l.
+func NewReloader(l *Loader) (*Reloader, error) {
+	r := &Reloader{loader: l, stat: statFile(l.Path)}
+	c, err := l.Load()
+	if err != nil {
+		return nil, err
+	}
+	r.current.Store(c)
+	return r, nil
+}
+
+// Current returns the configuration in effect. It must not be modified.
+func (r *Reloader) Current() *Config {
+	return r.current.Load().(*Config)
+}
+
+// OnReload adds a function called with the new configuration after each
+// successful reload, in the order added. It is called even if no setting
+// changed, since a reload also rereads the catalog directory.
+func (r *Reloader) OnReload(f func(*Config)) {
+	r.hooks = append(r.hooks, f)
+}
+
+// Reload loads the configuration again. If loading fails, the current
+// configuration stays in effect and the error is returned. Otherwise the
+// new configuration becomes current, with settings that need a restart
+// kept at their running values, and the OnReload functions run; the
+// returned error then wraps ErrRestartRequired if such settings changed.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stat = statFile(r.loader.Path)
+
+	next, err := r.loader.Load()
+	if err != nil {
+		return err
+	}
+	cur := r.Current()
+	var pending []string
+	ns, cs := settings(next), settings(cur)
+	for i, s := range ns {
+		if s.reload || reflect.DeepEqual(s.value.Interface(), cs[i].value.Interface()) {
+			continue
+		}
+		pending = append(pending, s.key)
+		s.value.Set(cs[i].value)
+	}
+	if len(pending) > 0 {
+		// The mix of old and new settings may not be valid together.
+		if err := next.Validate(); err != nil {
+			return fmt.Errorf("%w to change %s; not reloaded: %v", ErrRestartRequired, strings.Join(pending, ", "), err)
+		}
+	}
+
+	r.current.Store(next)
+	for _, f := range r.hooks {
+		f(next)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w to change %s", ErrRestartRequired, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// Watch reloads the configuration on SIGHUP and, if interval is positive,
+// whenever the file's modification time or size changes, checking every
+// interval. Reload errors go to report, if non-nil. Watch returns when ctx
+// is done.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration, report func(error)) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 && r.loader.Path != "" {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		tick = t.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		case <-tick:
+			if !r.fileChanged() {
+				continue
+			}
+		}
+		if err := r.Reload(); err != nil && report != nil {
+			report(err)
+		}
+	}
+}
+
+func (r *Reloader) fileChanged() bool {
+	st := statFile(r.loader.Path)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !st.modTime.Equal(r.stat.modTime) || st.size != r.stat.size
+}
+
+// statFile returns the zero fileStat if path is empty or cannot be read,
+// so a file that disappears counts as changed once.
+func statFile(path string) fileStat {
+	if path == "" {
+		return fileStat{}
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileStat{}
+	}
+	return fileStat{modTime: fi.ModTime(), size: fi.Size()}
+}
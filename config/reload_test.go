@@ -0,0 +1,108 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestReload tests which settings a reload applies
+func TestReload(t *testing.T) {
+	path := writeFile(t, "greet.yaml", "server:\n  addr: \":1000\"\nrate_limit:\n  rate: 1\n")
+	r, err := NewReloader(&Loader{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reloaded []*Config
+	r.OnReload(func(c *Config) { reloaded = append(reloaded, c) })
+
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("server:\n  addr: \":1000\"\nrate_limit:\n  rate: 2\ngreeting:\n  locale: de\n")
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload() = %v", err)
+	}
+	if c := r.Current(); c.RateLimit.Rate != 2 || c.Greeting.Locale != "de" {
+		t.Errorf("after reload: %+v", c)
+	}
+
+	write("server:\n  addr: \":2000\"\n  metrics: false\nrate_limit:\n  rate: 3\n")
+	err = r.Reload()
+	if !errors.Is(err, ErrRestartRequired) || !strings.Contains(err.Error(), "server.addr, server.metrics") {
+		t.Errorf("structural change: Reload() = %v", err)
+	}
+	if c := r.Current(); c.Server.Addr != ":1000" || !c.Server.Metrics || c.RateLimit.Rate != 3 {
+		t.Errorf("structural settings changed or reloadable ones not: %+v", c)
+	}
+
+	write("rate_limit:\n  rate: fast\n")
+	if err := r.Reload(); err == nil || errors.Is(err, ErrRestartRequired) {
+		t.Errorf("bad file: Reload() = %v", err)
+	}
+	if c := r.Current(); c.RateLimit.Rate != 3 {
+		t.Errorf("bad file replaced the configuration: %+v", c)
+	}
+	if len(reloaded) != 2 {
+		t.Errorf("OnReload ran %d times, want 2", len(reloaded))
+	}
+}
+
+// TestReloadInvalidMix tests a reload whose kept settings conflict with the new ones
+func TestReloadInvalidMix(t *testing.T) {
+	path := writeFile(t, "greet.yaml", "rate_limit:\n  enabled: true\n")
+	r, err := NewReloader(&Loader{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("rate_limit:\n  enabled: false\n  rate: 0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reload(); !errors.Is(err, ErrRestartRequired) || !strings.Contains(err.Error(), "not reloaded") {
+		t.Errorf("Reload() = %v", err)
+	}
+	if c := r.Current(); c.RateLimit.Rate != 5 {
+		t.Errorf("invalid mix applied: %+v", c.RateLimit)
+	}
+}
+
+// TestReloaderWatch tests reloading when the file changes
+func TestReloaderWatch(t *testing.T) {
+	path := writeFile(t, "greet.yaml", "greeting:\n  locale: en\n")
+	r, err := NewReloader(&Loader{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	locales := make(chan string, 10)
+	r.OnReload(func(c *Config) { locales <- c.Greeting.Locale })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Watch(ctx, 5*time.Millisecond, func(err error) { t.Errorf("reload: %v", err) })
+		close(done)
+	}()
+
+	if err := os.WriteFile(path, []byte("greeting:\n  locale: pt-BR\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case locale := <-locales:
+		if locale != "pt-BR" {
+			t.Errorf("reloaded locale %q", locale)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("file change not noticed")
+	}
+	cancel()
+	<-done
+	if r.Current().Greeting.Locale != "pt-BR" {
+		t.Errorf("Current() = %+v", r.Current().Greeting)
+	}
+}
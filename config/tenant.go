@@ -0,0 +1,191 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zhangbaodong/test"
+)
+
+// Tenant is the profile of one product served by the greeting server: the
+// requests that belong to it and how they are greeted, limited and
+// rendered.
+//
+// A profile file sets only what differs from the main configuration, e.g.
+//
+//	hosts: [greet.acme.example]
+//	prefixes: [/acme]
+//	greeting:
+//	  locale: de
+//	  default_name: Kunde
+//	branding:
+//	  title: ACME Greetings
+//	  accent: "#d9480f"
+type Tenant struct {
+	// Name identifies the tenant; it is the file name without extension.
+	Name string `json:"-" yaml:"-" toml:"-"`
+
+	Hosts    []string `json:"hosts" yaml:"hosts" toml:"hosts"`
+	Prefixes []string `json:"prefixes" yaml:"prefixes" toml:"prefixes"`
+	APIKeys  []string `json:"api_keys" yaml:"api_keys" toml:"api_keys"`
+
+	// Greeting starts from the main configuration's strategy, locale,
+	// formality and default name; the catalog and templates are the
+	// tenant's own. A relative catalog is found in the tenants directory.
+	Greeting Greeting `json:"greeting" yaml:"greeting" toml:"greeting"`
+
+	// RateLimit starts from the main configuration's, but every tenant
+	// has its own limiter.
+	RateLimit RateLimit `json:"rate_limit" yaml:"rate_limit" toml:"rate_limit"`
+
+	Branding Branding `json:"branding" yaml:"branding" toml:"branding"`
+}
+
+// Branding customizes a tenant's HTML page.
+type Branding struct {
+	Title      string `json:"title" yaml:"title" toml:"title"`
+	Accent     string `json:"accent" yaml:"accent" toml:"accent"`             // a CSS color such as "#d9480f" or "teal"
+	Stylesheet string `json:"stylesheet" yaml:"stylesheet" toml:"stylesheet"` // CSS added to the page as is
+}
+
+// Validate checks the profile, reporting all problems at once as a
+// *ValidationError.
+func (t *Tenant) Validate() error {
+	var problems []string
+	add := func(key, format string, args ...interface{}) {
+		problems = append(problems, key+": "+fmt.Sprintf(format, args...))
+	}
+
+	if len(t.Hosts)+len(t.Prefixes)+len(t.APIKeys) == 0 {
+		add("hosts", "a tenant needs hosts, prefixes or api_keys")
+	}
+	for _, p := range t.Prefixes {
+		if !strings.HasPrefix(p, "/") || strings.Trim(p, "/") == "" {
+			add("prefixes", "%q must start with / and name a path", p)
+		}
+	}
+	for _, k := range t.APIKeys {
+		if k == "" {
+			add("api_keys", "must not be empty")
+		}
+	}
+
+	validateGreeting(&t.Greeting, "greeting", add)
+	if err := test.ValidateName(t.Greeting.DefaultName); err != nil {
+		add("greeting.default_name", "%v", err)
+	}
+	validateRateLimit(&t.RateLimit, "rate_limit", add)
+
+	for _, r := range t.Branding.Accent {
+		if !(r == '#' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			add("branding.accent", "%q is not a color name or #hex value", t.Branding.Accent)
+			break
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// TenantError reports the tenant profiles LoadTenants could not load.
+type TenantError struct {
+	Failed map[string]error // by tenant name
+}
+
+func (e *TenantError) Error() string {
+	names := make([]string, 0, len(e.Failed))
+	for name := range e.Failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	msgs := make([]string, len(names))
+	for i, name := range names {
+		msgs[i] = "tenant " + name + ": " + e.Failed[name].Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// LoadTenants loads the tenant profiles in dir: one .yaml, .yml, .json or
+// .toml file per tenant, named after it. Other files and directories are
+// ignored. Profiles start from base's greeting and rate limit settings.
+//
+// Each profile is loaded and validated on its own, so a broken profile
+// cannot affect the others: LoadTenants returns every valid tenant, sorted
+// by name, and reports the rest in a *TenantError. Only failing to read
+// dir returns no tenants.
+//
+// Example:
+//
+//	tenants, err := config.LoadTenants(cfg.Tenants.Dir, cfg)
+//	if err != nil {
+//		log.Print(err) // serve the valid tenants anyway
+//	}
+func LoadTenants(dir string, base *Config) ([]*Tenant, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+
+	paths := make(map[string]string)
+	failed := make(map[string]error)
+	for _, e := range entries {
+		ext := strings.ToLower(filepath.Ext(e.Name()))
+		if e.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json" && ext != ".toml") {
+			continue
+		}
+		name := strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))
+		if prev, dup := paths[name]; dup {
+			failed[name] = fmt.Errorf("defined by both %s and %s", filepath.Base(prev), e.Name())
+			continue
+		}
+		paths[name] = filepath.Join(dir, e.Name())
+	}
+
+	var tenants []*Tenant
+	for name, path := range paths {
+		if failed[name] != nil {
+			continue
+		}
+		t, err := loadTenant(name, path, dir, base)
+		if err != nil {
+			failed[name] = err
+			continue
+		}
+		tenants = append(tenants, t)
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].Name < tenants[j].Name })
+
+	if len(failed) > 0 {
+		return tenants, &TenantError{Failed: failed}
+	}
+	return tenants, nil
+}
+
+// loadTenant loads and validates the profile of tenant name from path.
+func loadTenant(name, path, dir string, base *Config) (*Tenant, error) {
+	t := &Tenant{
+		Name: name,
+		Greeting: Greeting{
+			Strategy:    base.Greeting.Strategy,
+			Locale:      base.Greeting.Locale,
+			Formal:      base.Greeting.Formal,
+			DefaultName: base.Greeting.DefaultName,
+		},
+		RateLimit: base.RateLimit,
+	}
+	if err := decodeFile(t, path); err != nil {
+		return nil, err
+	}
+	if t.Greeting.Catalog != "" && !filepath.IsAbs(t.Greeting.Catalog) {
+		t.Greeting.Catalog = filepath.Join(dir, t.Greeting.Catalog)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
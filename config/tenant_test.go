@@ -0,0 +1,130 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTenants writes tenant profiles into a temporary directory
+func writeTenants(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// TestLoadTenants tests loading profiles in every format over the base settings
+func TestLoadTenants(t *testing.T) {
+	dir := writeTenants(t, map[string]string{
+		"acme.yaml": `
+hosts: [greet.acme.example]
+prefixes: [/acme]
+greeting:
+  locale: de
+  catalog: acme-messages
+branding:
+  title: ACME
+  accent: "#d9480f"
+`,
+		"acme-messages/de.json": `{"greeting": "Servus, {name}"}`,
+		"globex.json":           `{"api_keys": ["k1"], "rate_limit": {"burst": 3}}`,
+		"initech.toml":          "prefixes = [\"/initech\"]\n[greeting]\ndefault_name = \"Peter\"\n",
+		"README.md":             "not a profile",
+	})
+	base := Default()
+	base.Greeting.DefaultName = "Kunde"
+	base.RateLimit.Enabled = true
+
+	tenants, err := LoadTenants(dir, &base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tn := range tenants {
+		names = append(names, tn.Name)
+	}
+	if strings.Join(names, ",") != "acme,globex,initech" {
+		t.Fatalf("tenants = %v", names)
+	}
+
+	acme, globex, initech := tenants[0], tenants[1], tenants[2]
+	g, err := acme.Greeting.Greeter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := g.SayHiStrict("Anna"); result != "Servus, Anna" {
+		t.Errorf("acme greeting = %q", result)
+	}
+	if acme.Branding.Title != "ACME" || acme.Greeting.Strategy != base.Greeting.Strategy || acme.Greeting.DefaultName != "Kunde" {
+		t.Errorf("acme = %+v", acme)
+	}
+	if !globex.RateLimit.Enabled || globex.RateLimit.Rate != base.RateLimit.Rate || globex.RateLimit.Burst != 3 {
+		t.Errorf("globex rate limit = %+v", globex.RateLimit)
+	}
+	if initech.Greeting.DefaultName != "Peter" || initech.Greeting.Locale != "en" {
+		t.Errorf("initech greeting = %+v", initech.Greeting)
+	}
+}
+
+// TestLoadTenantsIsolation tests that broken profiles do not affect the others
+func TestLoadTenantsIsolation(t *testing.T) {
+	dir := writeTenants(t, map[string]string{
+		"good.yaml":      "prefixes: [/good]\n",
+		"typo.yaml":      "prefixes: [/typo]\ngreting:\n  locale: de\n",
+		"badlocale.json": `{"prefixes": ["/bad"], "greeting": {"locale": "??"}}`,
+		"noroutes.yaml":  "branding:\n  title: Lost\n",
+		"style.yaml":     "hosts: [style.example]\nbranding:\n  accent: \"red; background: url(x)\"\n",
+		"twice.yaml":     "prefixes: [/twice]\n",
+		"twice.toml":     "prefixes = [\"/twice\"]\n",
+		"root.yaml":      "prefixes: [/]\n",
+	})
+	base := Default()
+
+	tenants, err := LoadTenants(dir, &base)
+	if len(tenants) != 1 || tenants[0].Name != "good" {
+		t.Errorf("valid tenants = %v", tenants)
+	}
+	var te *TenantError
+	if !errors.As(err, &te) {
+		t.Fatalf("error %v is not a *TenantError", err)
+	}
+	for _, name := range []string{"typo", "badlocale", "noroutes", "style", "twice", "root"} {
+		if te.Failed[name] == nil {
+			t.Errorf("tenant %s did not fail", name)
+		}
+	}
+	if len(te.Failed) != 6 {
+		t.Errorf("failed = %v", te.Failed)
+	}
+	if !strings.Contains(err.Error(), "tenant typo: ") || !strings.Contains(te.Failed["typo"].Error(), "greting") {
+		t.Errorf("error = %v", err)
+	}
+
+	if _, err := LoadTenants(filepath.Join(dir, "missing"), &base); err == nil || errors.As(err, &te) {
+		t.Errorf("missing directory: %v", err)
+	}
+}
+
+// TestValidateTenantsDir tests validation of the tenants directory setting
+func TestValidateTenantsDir(t *testing.T) {
+	c := Default()
+	c.Tenants.Dir = writeFile(t, "file.yaml", "")
+	if err := c.Validate(); err == nil || !strings.Contains(err.Error(), "tenants.dir") {
+		t.Errorf("Validate = %v", err)
+	}
+	c.Tenants.Dir = filepath.Dir(c.Tenants.Dir)
+	if err := c.Validate(); err != nil {
+		t.Errorf("Validate = %v", err)
+	}
+}
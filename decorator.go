@@ -0,0 +1,74 @@
+package test
+
+import "time"
+
+// Decorator wraps a Greeter with behavior that applies whatever the
+// strategy, such as normalization, caching or auditing.
+type Decorator func(next Greeter) Greeter
+
+// Chain returns g wrapped in decorators, the first outermost, so
+// Chain(g, a, b) greets through a, then b, then g.
+//
+// Example:
+//
+//	g, _ := NewStrategy(StrategyFormal, "de")
+//	g = Chain(g,
+//		Auditing(func(r AuditRecord) { log.Printf("greeted in %v, err %v", r.Duration, r.Err) }),
+//		Normalizing(),
+//		Caching(cache, "formal/de"))
+func Chain(g Greeter, decorators ...Decorator) Greeter {
+	for i := len(decorators) - 1; i >= 0; i-- {
+		g = decorators[i](g)
+	}
+	return g
+}
+
+// Normalizing normalizes and validates names the way SayHiStrict does
+// before passing them on, rejecting invalid ones with a *NameError. It
+// gives custom strategies the name handling of the built-in ones.
+func Normalizing() Decorator {
+	return func(next Greeter) Greeter {
+		return GreetFunc(func(name string) (string, error) {
+			n := normalize(name, false)
+			if err := validateName(n); err != nil {
+				return "", &NameError{Name: name, Err: err}
+			}
+			return next.SayHiStrict(n)
+		})
+	}
+}
+
+// Caching looks greetings up in c before asking next for them. Keys are
+// the name and scope, which must tell apart the greeters sharing c, e.g.
+// "formal/de"; they never collide with the keys of a LocaleGreeter created
+// with WithCache. Rejected names are not cached.
+func Caching(c *GreetingCache, scope string) Decorator {
+	return func(next Greeter) Greeter {
+		return GreetFunc(func(name string) (string, error) {
+			return c.GetOrCompute(CacheKey{Name: name, Template: "scope:" + scope}, func() (string, error) {
+				return next.SayHiStrict(name)
+			})
+		})
+	}
+}
+
+// AuditRecord describes one greeting seen by Auditing.
+type AuditRecord struct {
+	Name     string // the name as passed in
+	Greeting string // empty if Err is set
+	Err      error
+	Duration time.Duration
+}
+
+// Auditing calls audit after every greeting, successful or not. Names are
+// personal data; redact them before they leave the process.
+func Auditing(audit func(AuditRecord)) Decorator {
+	return func(next Greeter) Greeter {
+		return GreetFunc(func(name string) (string, error) {
+			start := time.Now()
+			greeting, err := next.SayHiStrict(name)
+			audit(AuditRecord{Name: name, Greeting: greeting, Err: err, Duration: time.Since(start)})
+			return greeting, err
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// tracing returns a decorator that records its label when called
+func tracing(label string, trace *[]string) Decorator {
+	return func(next Greeter) Greeter {
+		return GreetFunc(func(name string) (string, error) {
+			*trace = append(*trace, label)
+			return next.SayHiStrict(name)
+		})
+	}
+}
+
+// TestChain tests that decorators run outermost first
+func TestChain(t *testing.T) {
+	var trace []string
+	g := Chain(GreetFunc(func(name string) (string, error) {
+		trace = append(trace, "greeter")
+		return "Hi, " + name, nil
+	}), tracing("a", &trace), tracing("b", &trace))
+
+	if result, err := g.SayHiStrict("Alice"); err != nil || result != "Hi, Alice" {
+		t.Errorf("SayHiStrict = %q, %v", result, err)
+	}
+	if strings.Join(trace, ",") != "a,b,greeter" {
+		t.Errorf("order = %v", trace)
+	}
+	lg, _ := NewGreeter("en")
+	if Chain(lg) != Greeter(lg) {
+		t.Error("Chain without decorators wrapped the greeter")
+	}
+}
+
+// TestNormalizing tests that names are normalized and validated
+func TestNormalizing(t *testing.T) {
+	var seen []string
+	g := Chain(GreetFunc(func(name string) (string, error) {
+		seen = append(seen, name)
+		return "Yo " + name, nil
+	}), Normalizing())
+
+	tests := []struct {
+		name     string
+		expected string
+		err      error
+	}{
+		{"  José \t Müller ", "Yo José Müller", nil},
+		{"José", "Yo José", nil},
+		{"   ", "", ErrEmptyName},
+		{"Al\x00ice", "", ErrControlChars},
+		{strings.Repeat("a", MaxNameLength+1), "", ErrTooLong},
+	}
+
+	for _, tt := range tests {
+		result, err := g.SayHiStrict(tt.name)
+		if result != tt.expected || !errors.Is(err, tt.err) {
+			t.Errorf("SayHiStrict(%q) = %q, %v, want %q, %v", tt.name, result, err, tt.expected, tt.err)
+		}
+	}
+	if len(seen) != 2 {
+		t.Errorf("invalid names reached the greeter: %q", seen)
+	}
+}
+
+// TestCaching tests that greetings are computed once per name and scope
+func TestCaching(t *testing.T) {
+	cache := NewGreetingCache(CacheConfig{MaxEntries: 10})
+	calls := 0
+	base := GreetFunc(func(name string) (string, error) {
+		calls++
+		if name == "" {
+			return "", ErrEmptyName
+		}
+		return "Hi, " + name, nil
+	})
+	g := Chain(base, Caching(cache, "a"))
+	other := Chain(GreetFunc(func(name string) (string, error) { return "Yo " + name, nil }), Caching(cache, "b"))
+
+	for i := 0; i < 3; i++ {
+		if result, _ := g.SayHiStrict("Alice"); result != "Hi, Alice" {
+			t.Errorf("SayHiStrict = %q", result)
+		}
+	}
+	if result, _ := other.SayHiStrict("Alice"); result != "Yo Alice" {
+		t.Errorf("other scope = %q", result)
+	}
+	g.SayHiStrict("")
+	g.SayHiStrict("")
+	if calls != 3 {
+		t.Errorf("greeter called %d times, want 3", calls)
+	}
+	if st := cache.Stats(); st.Entries != 2 {
+		t.Errorf("cache holds %d entries, want 2", st.Entries)
+	}
+}
+
+// TestAuditing tests that every greeting is reported
+func TestAuditing(t *testing.T) {
+	var mu sync.Mutex
+	var records []AuditRecord
+	g := Chain(GreetFunc(SayHiStrict), Auditing(func(r AuditRecord) {
+		mu.Lock()
+		records = append(records, r)
+		mu.Unlock()
+	}))
+
+	g.SayHiStrict("Alice")
+	g.SayHiStrict("")
+
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if r := records[0]; r.Name != "Alice" || r.Greeting != "Hi, Alice" || r.Err != nil || r.Duration < 0 {
+		t.Errorf("record 0 = %+v", r)
+	}
+	if r := records[1]; r.Greeting != "" || !errors.Is(r.Err, ErrEmptyName) {
+		t.Errorf("record 1 = %+v", r)
+	}
+}
@@ -0,0 +1,272 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Encoding is the context a greeting is embedded in, which decides how it
+// is escaped. Greetings echo names from users, so they must be escaped for
+// wherever they end up.
+type Encoding int
+
+const (
+	// EncodingRaw leaves the greeting as is.
+	EncodingRaw Encoding = iota
+	// EncodingHTML escapes for HTML text and quoted attribute values, like
+	// html.EscapeString.
+	EncodingHTML
+	// EncodingJSON produces a quoted JSON string that decodes to the same
+	// value as json.Marshal's, safe to embed in HTML <script> elements too.
+	// Backspace and form feed are written as \u0008 and \u000c where
+	// json.Marshal writes \b and \f.
+	EncodingJSON
+	// EncodingShell produces a single POSIX shell word in single quotes. NUL
+	// bytes, which no argument can hold, become U+FFFD.
+	EncodingShell
+	// EncodingCSV produces one RFC 4180 field, quoted when needed. Fields a
+	// spreadsheet would run as a formula, starting with = + - @ tab or
+	// carriage return, are prefixed with a single quote.
+	EncodingCSV
+	// EncodingMarkdown backslash-escapes ASCII punctuation so the greeting
+	// renders as plain inline text, and turns line breaks into spaces.
+	EncodingMarkdown
+)
+
+var encodingNames = [...]string{"raw", "html", "json", "shell", "csv", "markdown"}
+
+// String returns the encoding's lower-case name, e.g. "json".
+func (e Encoding) String() string {
+	if e >= 0 && int(e) < len(encodingNames) {
+		return encodingNames[e]
+	}
+	return fmt.Sprintf("Encoding(%d)", int(e))
+}
+
+// ParseEncoding returns the Encoding named s, as returned by String.
+func ParseEncoding(s string) (Encoding, error) {
+	for i, name := range encodingNames {
+		if s == name {
+			return Encoding(i), nil
+		}
+	}
+	return EncodingRaw, fmt.Errorf("unknown encoding %q", s)
+}
+
+// AppendEscaped appends s escaped for e to dst and returns the extended
+// buffer. Unknown encodings append s unchanged.
+func AppendEscaped(dst []byte, s string, e Encoding) []byte {
+	return appendEscaped(dst, s, e)
+}
+
+// text is what the escapers read: strings from callers, and the pooled
+// bytes of a Rendered, which they escape without copying.
+type text interface {
+	string | []byte
+}
+
+func appendEscaped[T text](dst []byte, s T, e Encoding) []byte {
+	switch e {
+	case EncodingHTML:
+		return appendHTML(dst, s)
+	case EncodingJSON:
+		return appendJSON(dst, s)
+	case EncodingShell:
+		return appendShell(dst, s)
+	case EncodingCSV:
+		return appendCSV(dst, s)
+	case EncodingMarkdown:
+		return appendMarkdown(dst, s)
+	}
+	return append(dst, s...)
+}
+
+// SayHiEncoded returns SayHi(name) escaped for e. The greeting is rendered
+// once, as by AppendGreeting, and escaped as a whole, so catalog or
+// template text is escaped along with the name.
+//
+// Example:
+//
+//	fmt.Fprintf(w, `{"greeting": %s}`, SayHiEncoded(name, EncodingJSON))
+//
+// Thread Safety:
+//   This function is safe for concurrent use by multiple goroutines.
+func SayHiEncoded(name string, e Encoding) string {
+	return encodeGreeting(Render(name), e)
+}
+
+// SayHiHTML returns SayHi(name) escaped for HTML text or attributes.
+func SayHiHTML(name string) string {
+	return SayHiEncoded(name, EncodingHTML)
+}
+
+// SayHiJSON returns SayHi(name) as a quoted JSON string.
+func SayHiJSON(name string) string {
+	return SayHiEncoded(name, EncodingJSON)
+}
+
+// SayHiShell returns SayHi(name) as a single-quoted POSIX shell word.
+func SayHiShell(name string) string {
+	return SayHiEncoded(name, EncodingShell)
+}
+
+// SayHiCSV returns SayHi(name) as one CSV field.
+func SayHiCSV(name string) string {
+	return SayHiEncoded(name, EncodingCSV)
+}
+
+// SayHiMarkdown returns SayHi(name) escaped as inline Markdown text.
+func SayHiMarkdown(name string) string {
+	return SayHiEncoded(name, EncodingMarkdown)
+}
+
+// SayHiEncoded returns the localized greeting for name escaped for e. See
+// the package-level SayHiEncoded.
+func (g *LocaleGreeter) SayHiEncoded(name string, e Encoding) string {
+	return encodeGreeting(g.Render(name), e)
+}
+
+// encodeGreeting returns r escaped for e and releases r. The greeting is
+// escaped straight from r into a second pooled buffer, so the returned
+// string is the only allocation.
+func encodeGreeting(r *Rendered, e Encoding) string {
+	// Room for the quotes and a few escapes; buffers that grow past their
+	// class are dropped on Release.
+	out := acquireRendered(r.Len() + r.Len()/2 + 2)
+	out.buf = appendEscaped(out.buf, r.buf, e)
+	s := string(out.buf)
+	out.Release()
+	r.Release()
+	return s
+}
+
+func appendHTML[T text](dst []byte, s T) []byte {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '&':
+			dst = append(dst, "&amp;"...)
+		case '<':
+			dst = append(dst, "&lt;"...)
+		case '>':
+			dst = append(dst, "&gt;"...)
+		case '"':
+			dst = append(dst, "&#34;"...)
+		case '\'':
+			dst = append(dst, "&#39;"...)
+		default:
+			dst = append(dst, c)
+		}
+	}
+	return dst
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSON follows encoding/json's string encoding with HTML escaping.
+func appendJSON[T text](dst []byte, s T) []byte {
+	dst = append(dst, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				dst = append(dst, '\\', c)
+			case c == '\n':
+				dst = append(dst, '\\', 'n')
+			case c == '\r':
+				dst = append(dst, '\\', 'r')
+			case c == '\t':
+				dst = append(dst, '\\', 't')
+			case c < 0x20 || c == '<' || c == '>' || c == '&':
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			default:
+				dst = append(dst, c)
+			}
+			i++
+			continue
+		}
+		// A rune is at most utf8.UTFMax bytes; converting no more keeps
+		// the conversion of []byte input off the heap.
+		end := i + utf8.UTFMax
+		if end > len(s) {
+			end = len(s)
+		}
+		r, size := utf8.DecodeRuneInString(string(s[i:end]))
+		switch {
+		case r == utf8.RuneError && size == 1:
+			dst = append(dst, "\uFFFD"...)
+		case r == '\u2028' || r == '\u2029':
+			// Line terminators in JavaScript, though not in JSON.
+			dst = append(dst, '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+		default:
+			dst = append(dst, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(dst, '"')
+}
+
+func appendShell[T text](dst []byte, s T) []byte {
+	dst = append(dst, '\'')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\'':
+			// Close the quote, add an escaped quote, reopen.
+			dst = append(dst, `'\''`...)
+		case 0:
+			dst = append(dst, "\uFFFD"...)
+		default:
+			dst = append(dst, c)
+		}
+	}
+	return append(dst, '\'')
+}
+
+func appendCSV[T text](dst []byte, s T) []byte {
+	formula := len(s) > 0 && (s[0] == '=' || s[0] == '+' || s[0] == '-' || s[0] == '@' || s[0] == '\t' || s[0] == '\r')
+	quote := len(s) == 0 || s[0] == ' ' || formula
+	for i := 0; i < len(s) && !quote; i++ {
+		switch s[i] {
+		case ',', '"', '\r', '\n':
+			quote = true
+		}
+	}
+	if !quote {
+		return append(dst, s...)
+	}
+
+	dst = append(dst, '"')
+	if formula {
+		dst = append(dst, '\'')
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' {
+			dst = append(dst, '"')
+		}
+		dst = append(dst, s[i])
+	}
+	return append(dst, '"')
+}
+
+func appendMarkdown[T text](dst []byte, s T) []byte {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\r' && i+1 < len(s) && s[i+1] == '\n':
+			// One space for a CRLF pair.
+		case c == '\r' || c == '\n':
+			dst = append(dst, ' ')
+		case strings.IndexByte(markdownSpecial, c) >= 0:
+			dst = append(dst, '\\', c)
+		default:
+			dst = append(dst, c)
+		}
+	}
+	return dst
+}
+
+// markdownSpecial lists the characters appendMarkdown escapes: those
+// starting emphasis, code, links, images, HTML, entities, headings, lists,
+// tables, strikethrough and autolinks.
+const markdownSpecial = "\\`*_{}[]<>()#+-.!|~&:"
@@ -0,0 +1,237 @@
+package test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestSayHiEncoders tests the context-specific greeting encoders
+func TestSayHiEncoders(t *testing.T) {
+	tests := []struct {
+		encode   func(string) string
+		name     string
+		expected string
+	}{
+		{SayHiHTML, "Alice", "Hi, Alice"},
+		{SayHiHTML, `<script>alert("x")</script>`, "Hi, &lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;"},
+		{SayHiHTML, "O'Brien & Co", "Hi, O&#39;Brien &amp; Co"},
+		{SayHiJSON, "Alice", `"Hi, Alice"`},
+		{SayHiJSON, `"}`, `"Hi, \"}"`},
+		{SayHiJSON, "a\nb\x00</script>", `"Hi, a\nb\u0000\u003c/script\u003e"`},
+		{SayHiShell, "Alice", `'Hi, Alice'`},
+		{SayHiShell, "O'Brien; rm -rf /", `'Hi, O'\''Brien; rm -rf /'`},
+		{SayHiShell, "$(id) `id`", "'Hi, $(id) `id`'"},
+		{SayHiCSV, "Alice", `"Hi, Alice"`},
+		{SayHiCSV, `say "hi"`, `"Hi, say ""hi"""`},
+		{SayHiMarkdown, "Alice", `Hi, Alice`},
+		{SayHiMarkdown, "*bold* [x](http://e.com)", `Hi, \*bold\* \[x\]\(http\://e\.com\)`},
+		{SayHiMarkdown, "a\r\n# b", `Hi, a \# b`},
+	}
+
+	for i, tt := range tests {
+		if result := tt.encode(tt.name); result != tt.expected {
+			t.Errorf("case %d: encoding %q = %s, want %s", i, tt.name, result, tt.expected)
+		}
+	}
+	if result := SayHiEncoded("<b>", EncodingRaw); result != SayHi("<b>") {
+		t.Errorf("raw = %q", result)
+	}
+}
+
+// TestAppendEscapedCSV tests CSV quoting and formula neutralization
+func TestAppendEscapedCSV(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"", `""`},
+		{"a,b", `"a,b"`},
+		{" padded", `" padded"`},
+		{"line\nbreak", "\"line\nbreak\""},
+		{"=HYPERLINK(\"http://x\")", `"'=HYPERLINK(""http://x"")"`},
+		{"+1", `"'+1"`},
+		{"-1", `"'-1"`},
+		{"@SUM(A1)", `"'@SUM(A1)"`},
+		{"\tx", "\"'\tx\""},
+	}
+
+	for _, tt := range tests {
+		if result := string(AppendEscaped(nil, tt.input, EncodingCSV)); result != tt.expected {
+			t.Errorf("CSV %q = %s, want %s", tt.input, result, tt.expected)
+		}
+	}
+}
+
+// TestLocaleGreeterSayHiEncoded tests escaping localized greetings
+func TestLocaleGreeterSayHiEncoded(t *testing.T) {
+	c := NewCatalog()
+	if err := c.Set("en", MessageGreeting, `<b>Hey</b> "{name}"`); err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGreeter("en", WithCatalog(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := g.SayHiEncoded("Al & Bo", EncodingHTML); result != "&lt;b&gt;Hey&lt;/b&gt; &#34;Al &amp; Bo&#34;" {
+		t.Errorf("HTML = %s", result)
+	}
+	var decoded string
+	if err := json.Unmarshal([]byte(g.SayHiEncoded(`"}`, EncodingJSON)), &decoded); err != nil || decoded != g.SayHi(`"}`) {
+		t.Errorf("JSON decoded to %q, %v", decoded, err)
+	}
+}
+
+// TestParseEncoding tests encoding names
+func TestParseEncoding(t *testing.T) {
+	for e := EncodingRaw; e <= EncodingMarkdown; e++ {
+		if parsed, err := ParseEncoding(e.String()); err != nil || parsed != e {
+			t.Errorf("ParseEncoding(%q) = %v, %v", e.String(), parsed, err)
+		}
+	}
+	if _, err := ParseEncoding("xml"); err == nil {
+		t.Error("ParseEncoding accepted xml")
+	}
+	if s := Encoding(42).String(); s != "Encoding(42)" {
+		t.Errorf("String() = %s", s)
+	}
+}
+
+// TestShellQuotingWithShell tests that sh reads quoted greetings back unchanged
+func TestShellQuotingWithShell(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no sh in PATH")
+	}
+	for _, name := range []string{"O'Brien", "$(id) `id` $HOME", `a\b "c"`, "line\nbreak", "!!;|&<>*?~#"} {
+		out, err := exec.Command(sh, "-c", "printf %s "+SayHiShell(name)).Output()
+		if err != nil {
+			t.Fatalf("sh: %v", err)
+		}
+		if string(out) != SayHi(name) {
+			t.Errorf("sh read %q back as %q", name, out)
+		}
+	}
+}
+
+// FuzzAppendEscaped tests that every encoding round-trips and cannot be broken out of
+func FuzzAppendEscaped(f *testing.F) {
+	for _, seed := range []string{
+		"Alice", "", `"}`, "</script><script>", "O'Brien", "=cmd|' /C calc'!A0",
+		"a\r\nb", "\x00", "\xff\xfe", " ", "*_`[]()#", "日本語", "\\'\\",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		if out := string(AppendEscaped(nil, s, EncodingHTML)); out != html.EscapeString(s) ||
+			html.UnescapeString(out) != s || strings.ContainsAny(out, `<>"'`) {
+			t.Errorf("HTML %q = %q", s, out)
+		}
+
+		out := AppendEscaped(nil, s, EncodingJSON)
+		var decoded, want string
+		marshaled, _ := json.Marshal(s)
+		json.Unmarshal(marshaled, &want)
+		if err := json.Unmarshal(out, &decoded); err != nil || decoded != want || strings.ContainsAny(string(out), "<>&\n\r\u2028") {
+			t.Errorf("JSON %q = %s, decoded as %q, %v", s, out, decoded, err)
+		}
+
+		if got, err := unquoteShell(string(AppendEscaped(nil, s, EncodingShell))); err != nil ||
+			got != strings.ReplaceAll(s, "\x00", "\uFFFD") {
+			t.Errorf("shell %q read back as %q, %v", s, got, err)
+		}
+
+		want = strings.ReplaceAll(s, "\r\n", "\n")
+		if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+			want = "'" + want
+		}
+		records, err := csv.NewReader(strings.NewReader(string(AppendEscaped(nil, s, EncodingCSV)))).ReadAll()
+		if err != nil || len(records) != 1 || len(records[0]) != 1 || records[0][0] != want {
+			t.Errorf("CSV %q read back as %q, %v", s, records, err)
+		}
+
+		want = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(s)
+		if got, err := unescapeMarkdown(string(AppendEscaped(nil, s, EncodingMarkdown))); err != nil || got != want {
+			t.Errorf("Markdown %q read back as %q, %v", s, got, err)
+		}
+
+		if got := SayHiEncoded(s, EncodingHTML); got != html.EscapeString(SayHi(s)) {
+			t.Errorf("SayHiHTML(%q) = %q", s, got)
+		}
+
+		for e := range encodingNames {
+			if got, want := appendEscaped(nil, []byte(s), Encoding(e)), AppendEscaped(nil, s, Encoding(e)); string(got) != string(want) {
+				t.Errorf("%v of bytes %q = %q, of the string %q", Encoding(e), s, got, want)
+			}
+		}
+	})
+}
+
+// TestSayHiEncodedAllocs tests that escaping a greeting allocates only the result
+func TestSayHiEncodedAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector drops pooled buffers at random")
+	}
+	g, err := NewGreeter("de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for e := range encodingNames {
+		if n := testing.AllocsPerRun(100, func() { SayHiEncoded("Zoë <Ann>", Encoding(e)) }); n != 1 {
+			t.Errorf("SayHiEncoded(%v) allocs = %v, want 1", Encoding(e), n)
+		}
+		if n := testing.AllocsPerRun(100, func() { g.SayHiEncoded("Zoë <Ann>", Encoding(e)) }); n != 1 {
+			t.Errorf("LocaleGreeter.SayHiEncoded(%v) allocs = %v, want 1", Encoding(e), n)
+		}
+	}
+}
+
+// unquoteShell reads back a word made of single-quoted strings and
+// backslash-escaped single quotes, rejecting anything a shell would expand
+func unquoteShell(word string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(word); {
+		switch {
+		case word[i] == '\'':
+			end := strings.IndexByte(word[i+1:], '\'')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated quote in %q", word)
+			}
+			b.WriteString(word[i+1 : i+1+end])
+			i += end + 2
+		case strings.HasPrefix(word[i:], `\'`):
+			b.WriteByte('\'')
+			i += 2
+		default:
+			return "", fmt.Errorf("unquoted %q in %q", word[i], word)
+		}
+	}
+	return b.String(), nil
+}
+
+// unescapeMarkdown removes backslash escapes, which CommonMark allows
+// before any ASCII punctuation, rejecting unescaped special characters
+// and line breaks
+func unescapeMarkdown(text string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 < len(text) && strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", text[i+1]) >= 0:
+			b.WriteByte(text[i+1])
+			i++
+		case strings.IndexByte(markdownSpecial, c) >= 0 || c == '\n' || c == '\r':
+			return "", fmt.Errorf("unescaped %q in %q", c, text)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
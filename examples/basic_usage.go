@@ -1,4 +1,8 @@
+//go:build ignore
+
 // Basic usage example for the test package
+//
+// Run it with: go run examples/basic_usage.go
 package main
 
 import (
@@ -1,8 +1,14 @@
-// Command-line interface example using the test package
+//go:build ignore
+
+// Command-line interface example using the test package.
+// See cmd/greet for a complete tool with subcommands and output formats.
+//
+// Run it with: go run examples/cli_app.go -name World
 package main
 
 import (
 	"bufio"
+	"context"
 	"flag"
 	"fmt"
 	"os"
@@ -53,7 +59,7 @@ func interactiveMode() {
 			break
 		}
 		
-		name := strings.TrimSpace(scanner.Text())
+		name := test.NormalizeName(scanner.Text())
 		
 		if name == "" {
 			continue
@@ -75,18 +81,12 @@ func interactiveMode() {
 
 // Process input from stdin
 func processStdin() {
-	scanner := bufio.NewScanner(os.Stdin)
-	
-	for scanner.Scan() {
-		name := strings.TrimSpace(scanner.Text())
-		if name != "" {
-			greeting := test.SayHi(name)
-			fmt.Println(greeting)
-		}
-	}
-	
-	if err := scanner.Err(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading from stdin: %v\n", err)
+	batch := test.NewBatchGreeter(nil, 0)
+	_, err := batch.GreetLines(context.Background(), os.Stdin, os.Stdout, func(r test.BatchResult) {
+		fmt.Fprintf(os.Stderr, "Skipping line %d: %v\n", r.Index+1, r.Err)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error processing stdin: %v\n", err)
 		os.Exit(1)
 	}
 }
@@ -95,7 +95,7 @@ func processStdin() {
 func showVersion() {
 	fmt.Printf("Greeting CLI v0.0.0\n")
 	fmt.Printf("Using test package: github.com/zhangbaodong/test\n")
-	fmt.Printf("Go version: 1.16+\n")
+	fmt.Printf("Go version: 1.18+\n")
 }
 
 func main() {
@@ -125,7 +125,11 @@ func main() {
 	
 	// Process command line argument
 	if config.name != "" {
-		greeting := test.SayHi(config.name)
+		greeting, err := test.SayHiStrict(config.name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 		fmt.Println(greeting)
 		return
 	}
@@ -1,4 +1,9 @@
+//go:build ignore
+
 // Testing example for the test package
+//
+// Run it with: go run examples/testing_example.go. The package runs the
+// same cases itself in say_fuzz_test.go.
 package main
 
 import (
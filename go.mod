@@ -1,3 +1,9 @@
 module github.com/zhangbaodong/test
 
-go 1.16
+go 1.18
+
+require (
+	github.com/BurntSushi/toml v1.3.2
+	golang.org/x/text v0.21.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,56 @@
+// Package greetcompress provides the Brotli ("br") and zstd ("zstd")
+// content codings for greethttp.Compress.
+//
+// Both are pure Go, without cgo. The package lives in its own module so the
+// core library keeps to the standard library, where greethttp's own Gzip
+// and Deflate codings come from.
+//
+//	h := greethttp.Compress(next, greethttp.WithEncodings(
+//		greetcompress.Brotli(brotli.DefaultCompression),
+//		greetcompress.Zstd(zstd.SpeedDefault),
+//		greethttp.Gzip(gzip.DefaultCompression),
+//	))
+package greetcompress
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/zhangbaodong/test/greethttp"
+)
+
+// Brotli returns the "br" coding at the given level, from brotli.BestSpeed
+// to brotli.BestCompression. It panics if level is out of range.
+func Brotli(level int) greethttp.Encoding {
+	if level < brotli.BestSpeed || level > brotli.BestCompression {
+		panic(fmt.Sprintf("greetcompress: invalid Brotli level %d", level))
+	}
+	return greethttp.Encoding{Name: "br", NewWriter: func(w io.Writer) greethttp.Compressor {
+		return brotli.NewWriterLevel(w, level)
+	}}
+}
+
+// Zstd returns the "zstd" coding at the given level. Windows stay within
+// the 8 MB that RFC 8878 requires HTTP clients to support, and each writer
+// compresses on the goroutine writing the response. It panics if level is
+// invalid.
+func Zstd(level zstd.EncoderLevel) greethttp.Encoding {
+	opts := []zstd.EOption{
+		zstd.WithEncoderLevel(level),
+		zstd.WithEncoderConcurrency(1),
+		zstd.WithWindowSize(zstdMaxWindow),
+	}
+	if _, err := zstd.NewWriter(io.Discard, opts...); err != nil {
+		panic("greetcompress: " + err.Error())
+	}
+	return greethttp.Encoding{Name: "zstd", NewWriter: func(w io.Writer) greethttp.Compressor {
+		zw, _ := zstd.NewWriter(w, opts...)
+		return zw
+	}}
+}
+
+// zstdMaxWindow is the largest window RFC 8878 lets HTTP responses use.
+const zstdMaxWindow = 8 << 20
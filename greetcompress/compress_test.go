@@ -0,0 +1,77 @@
+package greetcompress
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/zhangbaodong/test/greethttp"
+)
+
+// TestEncodings tests that responses compressed by each coding decode to the original body
+func TestEncodings(t *testing.T) {
+	body := strings.Repeat("Hi, Alice. ", 500)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, body)
+	})
+	h := greethttp.Compress(next, greethttp.WithEncodings(Brotli(brotli.DefaultCompression), Zstd(zstd.SpeedDefault)))
+
+	tests := []struct {
+		accept   string
+		encoding string
+		decode   func(r io.Reader) (io.Reader, error)
+	}{
+		{"br", "br", func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil }},
+		{"zstd", "zstd", func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) }},
+		{"zstd, br", "br", func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil }},
+		{"br;q=0.5, zstd", "zstd", func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) }},
+	}
+
+	for _, tt := range tests {
+		// Twice, so the second response reuses a pooled writer.
+		for i := 0; i < 2; i++ {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept-Encoding", tt.accept)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Encoding"); got != tt.encoding {
+				t.Fatalf("Accept-Encoding %q: Content-Encoding = %q, want %q", tt.accept, got, tt.encoding)
+			}
+			if rec.Body.Len() >= len(body) {
+				t.Errorf("%s: compressed %d bytes to %d", tt.encoding, len(body), rec.Body.Len())
+			}
+			r, err := tt.decode(bytes.NewReader(rec.Body.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := io.ReadAll(r); err != nil || string(got) != body {
+				t.Errorf("%s: decoded %d bytes, %v; want the %d byte body", tt.encoding, len(got), err, len(body))
+			}
+		}
+	}
+}
+
+// TestInvalidLevels tests that invalid levels panic when the coding is made
+func TestInvalidLevels(t *testing.T) {
+	for name, f := range map[string]func(){
+		"Brotli": func() { Brotli(12) },
+		"Zstd":   func() { Zstd(zstd.EncoderLevel(99)) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s accepted an invalid level", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
@@ -0,0 +1,13 @@
+module github.com/zhangbaodong/test/greetcompress
+
+go 1.25.0
+
+require (
+	github.com/andybalholm/brotli v1.2.6
+	github.com/klauspost/compress v1.20.1
+	github.com/zhangbaodong/test v0.0.0
+)
+
+require golang.org/x/text v0.21.0 // indirect
+
+replace github.com/zhangbaodong/test => ../
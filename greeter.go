@@ -0,0 +1,279 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// LocaleGreeter generates greetings in a specific language. The greeting
+// template is resolved from a message catalog and compiled once, when the
+// LocaleGreeter is created, so generating a greeting only costs the output
+// allocation. It is the Greeter behind the built-in strategies.
+//
+// Example:
+//
+//	g, err := NewGreeter("pt-BR")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println(g.SayHi("Alice")) // Output: Oi, Alice
+//
+// Thread Safety:
+//   A LocaleGreeter is immutable and safe for concurrent use by multiple
+//   goroutines.
+type LocaleGreeter struct {
+	tag      string
+	locale   string
+	template *Template
+	punct    string
+
+	// Formal greetings.
+	formality      Formality
+	formalTemplate *Template
+	addressing     addressing
+
+	// Time-of-day greetings. SayHi and friends use them when clock is set.
+	timeTemplate *Template
+	salutations  [4]string
+	periods      DayPeriods
+	location     *time.Location
+	clock        Clock
+
+	// Group greetings.
+	group groupFormat
+
+	cache *GreetingCache
+}
+
+// Option configures a LocaleGreeter.
+type Option func(*greeterConfig)
+
+type greeterConfig struct {
+	catalog   *Catalog
+	template  *Template
+	formality Formality
+	clock     Clock
+	location  *time.Location
+	timeZone  string
+	periods   *DayPeriods
+
+	groupThreshold *int
+	listLimit      int
+	oxfordComma    bool
+
+	cache *GreetingCache
+}
+
+// WithCatalog makes the LocaleGreeter resolve messages from c instead of the
+// built-in catalog.
+func WithCatalog(c *Catalog) Option {
+	return func(cfg *greeterConfig) {
+		cfg.catalog = c
+	}
+}
+
+// WithTemplate overrides the catalog's greeting templates, in every
+// register and in time-of-day mode. Locale-specific values such as {punct} and
+// {timeofday} are still resolved from the catalog.
+func WithTemplate(t *Template) Option {
+	return func(cfg *greeterConfig) {
+		cfg.template = t
+	}
+}
+
+// WithTimeOfDay switches SayHi, SayHiBytes and SayHiBuffer to time-of-day
+// mode: the salutation is picked from the current time of clock, e.g.
+// "Good evening, Alice". A nil clock means SystemClock.
+func WithTimeOfDay(clock Clock) Option {
+	return func(cfg *greeterConfig) {
+		if clock == nil {
+			clock = SystemClock
+		}
+		cfg.clock = clock
+	}
+}
+
+// WithLocation sets the timezone the time of day is judged in. Without it,
+// times are used in their own location.
+func WithLocation(loc *time.Location) Option {
+	return func(cfg *greeterConfig) {
+		cfg.location = loc
+	}
+}
+
+// WithTimeZone is like WithLocation but takes an IANA timezone name such as
+// "America/Sao_Paulo". NewGreeter fails if the timezone cannot be loaded.
+func WithTimeZone(name string) Option {
+	return func(cfg *greeterConfig) {
+		cfg.timeZone = name
+	}
+}
+
+// WithDayPeriods overrides the catalog's day period boundaries.
+func WithDayPeriods(p DayPeriods) Option {
+	return func(cfg *greeterConfig) {
+		cfg.periods = &p
+	}
+}
+
+// NewGreeter returns a LocaleGreeter for the BCP 47 language tag. Messages
+// missing for the tag are looked up along its fallback chain, e.g.
+// "pt-BR" → "pt" → "en".
+//
+// An error is returned if the tag is malformed, no locale in its fallback
+// chain provides a valid greeting template, or a time-of-day setting is
+// invalid.
+func NewGreeter(tag string, opts ...Option) (*LocaleGreeter, error) {
+	cfg := greeterConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.catalog == nil {
+		cfg.catalog = defaultCatalog()
+	}
+
+	canon, err := CanonicalTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &LocaleGreeter{
+		tag:       canon,
+		formality: cfg.formality,
+		clock:     cfg.clock,
+		location:  cfg.location,
+		cache:     cfg.cache,
+	}
+	c := cfg.catalog
+	g.punct, _, _ = c.Lookup(canon, MessagePunct)
+
+	if g.locale, g.template, err = lookupTemplate(c, canon, MessageGreeting, cfg.template); err != nil {
+		return nil, err
+	}
+	// Catalogs without formal or time-of-day messages greet the regular way.
+	lang := primaryLanguage(g.locale)
+	g.formalTemplate = g.template
+	if _, ok := lookupLanguage(c, canon, lang, MessageFormal); ok {
+		if _, g.formalTemplate, err = lookupTemplate(c, canon, MessageFormal, cfg.template); err != nil {
+			return nil, err
+		}
+	}
+	g.timeTemplate = g.template
+	if _, _, ok := c.Lookup(canon, MessageTimeOfDay); ok {
+		if _, g.timeTemplate, err = lookupTemplate(c, canon, MessageTimeOfDay, cfg.template); err != nil {
+			return nil, err
+		}
+	}
+	if g.addressing, err = loadAddressing(c, canon, lang); err != nil {
+		return nil, err
+	}
+	if g.group, err = loadGroupFormat(c, canon, lang, &cfg); err != nil {
+		return nil, err
+	}
+
+	for p := Morning; p <= Night; p++ {
+		g.salutations[p], _, _ = c.Lookup(canon, p.String())
+	}
+
+	switch {
+	case cfg.periods != nil:
+		if err := cfg.periods.Validate(); err != nil {
+			return nil, err
+		}
+		g.periods = *cfg.periods
+	default:
+		g.periods = DefaultDayPeriods
+		if s, locale, ok := c.Lookup(canon, MessageDayPeriods); ok {
+			if g.periods, err = ParseDayPeriods(s); err != nil {
+				return nil, fmt.Errorf("%q message for %s: %w", MessageDayPeriods, locale, err)
+			}
+		}
+	}
+
+	if cfg.timeZone != "" {
+		if g.location, err = time.LoadLocation(cfg.timeZone); err != nil {
+			return nil, err
+		}
+	}
+
+	return g, nil
+}
+
+// lookupTemplate resolves and compiles the template stored under key,
+// unless override is set. It returns the locale the template came from.
+func lookupTemplate(c *Catalog, tag, key string, override *Template) (string, *Template, error) {
+	src, locale, ok := c.Lookup(tag, key)
+	if override != nil {
+		if !ok {
+			locale = tag
+		}
+		return locale, override, nil
+	}
+	if !ok {
+		return "", nil, fmt.Errorf("no %q message for %s", key, tag)
+	}
+
+	t, err := ParseTemplate(src)
+	if err != nil {
+		return "", nil, fmt.Errorf("%q message for %s: %w", key, locale, err)
+	}
+	return locale, t, nil
+}
+
+// Tag returns the canonical language tag the greeter was created for.
+func (g *LocaleGreeter) Tag() string {
+	return g.tag
+}
+
+// Locale returns the catalog locale the greeting was resolved from, which
+// may be less specific than Tag when a fallback was used.
+func (g *LocaleGreeter) Locale() string {
+	return g.locale
+}
+
+// SayHi generates a localized greeting message for the given name.
+func (g *LocaleGreeter) SayHi(name string) string {
+	v := Values{Name: name}
+	t := g.prepare(&v)
+	if g.cache == nil || g.clock != nil {
+		return t.Render(&v)
+	}
+
+	key := CacheKey{Name: name, Locale: g.tag, Template: t.String(), Punct: v.Punct, Formality: g.formality}
+	if greeting, ok := g.cache.Get(key); ok {
+		return greeting
+	}
+	greeting := t.Render(&v)
+	g.cache.Set(key, greeting)
+	return greeting
+}
+
+// SayHiBytes returns the localized greeting as a byte slice.
+func (g *LocaleGreeter) SayHiBytes(name string) []byte {
+	v := Values{Name: name}
+	t := g.prepare(&v)
+	return t.Append(make([]byte, 0, t.Len(&v)), &v)
+}
+
+// SayHiBuffer writes the localized greeting to a bytes.Buffer.
+func (g *LocaleGreeter) SayHiBuffer(name string, buf *bytes.Buffer) {
+	v := Values{Name: name}
+	t := g.prepare(&v)
+	t.WriteBuffer(buf, &v)
+}
+
+// prepare fills in the locale- and time-dependent fields of v and returns
+// the template to render it with.
+func (g *LocaleGreeter) prepare(v *Values) *Template {
+	v.Punct = g.punct
+	switch {
+	case g.clock != nil:
+		g.setTimeOfDay(v, g.clock.Now())
+		return g.timeTemplate
+	case g.formality == Formal:
+		return g.formalTemplate
+	default:
+		return g.template
+	}
+}
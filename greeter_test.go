@@ -0,0 +1,155 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestCanonicalTag tests BCP 47 tag canonicalization
+func TestCanonicalTag(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"en", "en", false},
+		{"PT-br", "pt-BR", false},
+		{"pt_BR", "pt-BR", false},
+		{"zh-hant-tw", "zh-Hant-TW", false},
+		{"es-419", "es-419", false},
+		{"", "", true},
+		{"e", "", true},
+		{"en--US", "", true},
+		{"en-US-", "", true},
+		{"en-toolongsubtag", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := CanonicalTag(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CanonicalTag(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("CanonicalTag(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestFallbackChain tests the lookup order for tags
+func TestFallbackChain(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"en", []string{"en"}},
+		{"pt-BR", []string{"pt-BR", "pt", "en"}},
+		{"zh-Hant-TW", []string{"zh-Hant-TW", "zh-Hant", "zh", "en"}},
+		{"en-GB", []string{"en-GB", "en"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := FallbackChain(tt.input); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("FallbackChain(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestGreeterLocales tests greetings resolved from the built-in catalog
+func TestGreeterLocales(t *testing.T) {
+	tests := []struct {
+		tag      string
+		locale   string
+		expected string
+	}{
+		{"en", "en", "Hi, Alice"},
+		{"pt-BR", "pt-BR", "Oi, Alice"},
+		{"pt-PT", "pt", "Olá, Alice"},
+		{"ja", "ja", "こんにちは、Alice"},
+		{"xx-YY", "en", "Hi, Alice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			g, err := NewGreeter(tt.tag)
+			if err != nil {
+				t.Fatalf("NewGreeter(%q) error: %v", tt.tag, err)
+			}
+			if g.Locale() != tt.locale {
+				t.Errorf("Locale() = %q, want %q", g.Locale(), tt.locale)
+			}
+			if result := g.SayHi("Alice"); result != tt.expected {
+				t.Errorf("SayHi = %q, want %q", result, tt.expected)
+			}
+			if result := string(g.SayHiBytes("Alice")); result != tt.expected {
+				t.Errorf("SayHiBytes = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestGreeterEnglishMatchesSayHi tests that the default locale agrees with SayHi
+func TestGreeterEnglishMatchesSayHi(t *testing.T) {
+	g, err := NewGreeter(DefaultLocale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"", "Alice", "José Müller 张三 😀"} {
+		if got, want := g.SayHi(name), SayHi(name); got != want {
+			t.Errorf("LocaleGreeter.SayHi(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+// TestCatalogLoadDir tests user-supplied catalogs layered over the built-in one
+func TestCatalogLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "sv.json"), []byte(`{"greeting": "Hej {name}!"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.txt"), []byte("ignored"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := DefaultCatalog()
+	if err := c.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir error: %v", err)
+	}
+
+	g, err := NewGreeter("sv-SE", WithCatalog(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := g.SayHi("Alice"); result != "Hej Alice!" {
+		t.Errorf("SayHi = %q, want %q", result, "Hej Alice!")
+	}
+
+	// The shared built-in catalog must not see user-loaded locales.
+	if g, _ := NewGreeter("sv"); g.Locale() != DefaultLocale {
+		t.Errorf("built-in catalog resolved sv to %q", g.Locale())
+	}
+}
+
+// TestCatalogLoadDirInvalid tests that a bad file leaves the catalog unchanged
+func TestCatalogLoadDirInvalid(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "de.json"), []byte(`{"greeting": "Servus, {name}"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "fr.json"), []byte(`{not json`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := DefaultCatalog()
+	if err := c.LoadDir(dir); err == nil {
+		t.Fatal("LoadDir accepted invalid JSON")
+	}
+	if msg, _, _ := c.Lookup("de", MessageGreeting); msg != "Hallo, {name}" {
+		t.Errorf("partial load modified catalog: de greeting = %q", msg)
+	}
+}
@@ -0,0 +1,5 @@
+package greetpb
+
+// Regenerating requires protoc with protoc-gen-go and protoc-gen-go-grpc on
+// PATH.
+//go:generate protoc -I .. --go_out=.. --go_opt=paths=source_relative --go-grpc_out=.. --go-grpc_opt=paths=source_relative greetpb/greeter.proto
@@ -0,0 +1,368 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: greetpb/greeter.proto
+
+package greetpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GreetRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name to greet. It is normalized before greeting.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// BCP 47 language tag such as "en" or "pt-BR". Empty means "en".
+	Locale        string `protobuf:"bytes,2,opt,name=locale,proto3" json:"locale,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GreetRequest) Reset() {
+	*x = GreetRequest{}
+	mi := &file_greetpb_greeter_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GreetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GreetRequest) ProtoMessage() {}
+
+func (x *GreetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greetpb_greeter_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GreetRequest.ProtoReflect.Descriptor instead.
+func (*GreetRequest) Descriptor() ([]byte, []int) {
+	return file_greetpb_greeter_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GreetRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *GreetRequest) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+type GreetResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The greeting, e.g. "Hi, Alice".
+	Greeting string `protobuf:"bytes,1,opt,name=greeting,proto3" json:"greeting,omitempty"`
+	// The normalized name that was greeted.
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// The catalog locale the greeting came from, after fallback.
+	Locale        string `protobuf:"bytes,3,opt,name=locale,proto3" json:"locale,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GreetResponse) Reset() {
+	*x = GreetResponse{}
+	mi := &file_greetpb_greeter_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GreetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GreetResponse) ProtoMessage() {}
+
+func (x *GreetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greetpb_greeter_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GreetResponse.ProtoReflect.Descriptor instead.
+func (*GreetResponse) Descriptor() ([]byte, []int) {
+	return file_greetpb_greeter_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GreetResponse) GetGreeting() string {
+	if x != nil {
+		return x.Greeting
+	}
+	return ""
+}
+
+func (x *GreetResponse) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *GreetResponse) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+type GreetBatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Position of the request on the stream, starting at 0.
+	Index int64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// The greeting, if error is empty.
+	Result *GreetResponse `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+	// Why the record could not be greeted.
+	Error         string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GreetBatchResponse) Reset() {
+	*x = GreetBatchResponse{}
+	mi := &file_greetpb_greeter_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GreetBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GreetBatchResponse) ProtoMessage() {}
+
+func (x *GreetBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greetpb_greeter_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GreetBatchResponse.ProtoReflect.Descriptor instead.
+func (*GreetBatchResponse) Descriptor() ([]byte, []int) {
+	return file_greetpb_greeter_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GreetBatchResponse) GetIndex() int64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *GreetBatchResponse) GetResult() *GreetResponse {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *GreetBatchResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListLocalesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLocalesRequest) Reset() {
+	*x = ListLocalesRequest{}
+	mi := &file_greetpb_greeter_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLocalesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLocalesRequest) ProtoMessage() {}
+
+func (x *ListLocalesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greetpb_greeter_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLocalesRequest.ProtoReflect.Descriptor instead.
+func (*ListLocalesRequest) Descriptor() ([]byte, []int) {
+	return file_greetpb_greeter_proto_rawDescGZIP(), []int{3}
+}
+
+type ListLocalesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Canonical locale tags, sorted.
+	Locales       []string `protobuf:"bytes,1,rep,name=locales,proto3" json:"locales,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLocalesResponse) Reset() {
+	*x = ListLocalesResponse{}
+	mi := &file_greetpb_greeter_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLocalesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLocalesResponse) ProtoMessage() {}
+
+func (x *ListLocalesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greetpb_greeter_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLocalesResponse.ProtoReflect.Descriptor instead.
+func (*ListLocalesResponse) Descriptor() ([]byte, []int) {
+	return file_greetpb_greeter_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListLocalesResponse) GetLocales() []string {
+	if x != nil {
+		return x.Locales
+	}
+	return nil
+}
+
+var File_greetpb_greeter_proto protoreflect.FileDescriptor
+
+const file_greetpb_greeter_proto_rawDesc = "" +
+	"\n" +
+	"\x15greetpb/greeter.proto\x12\n" +
+	"greeter.v1\":\n" +
+	"\fGreetRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06locale\x18\x02 \x01(\tR\x06locale\"W\n" +
+	"\rGreetResponse\x12\x1a\n" +
+	"\bgreeting\x18\x01 \x01(\tR\bgreeting\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06locale\x18\x03 \x01(\tR\x06locale\"s\n" +
+	"\x12GreetBatchResponse\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x03R\x05index\x121\n" +
+	"\x06result\x18\x02 \x01(\v2\x19.greeter.v1.GreetResponseR\x06result\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\x14\n" +
+	"\x12ListLocalesRequest\"/\n" +
+	"\x13ListLocalesResponse\x12\x18\n" +
+	"\alocales\x18\x01 \x03(\tR\alocales2\xea\x01\n" +
+	"\x0eGreeterService\x12<\n" +
+	"\x05Greet\x12\x18.greeter.v1.GreetRequest\x1a\x19.greeter.v1.GreetResponse\x12J\n" +
+	"\n" +
+	"GreetBatch\x12\x18.greeter.v1.GreetRequest\x1a\x1e.greeter.v1.GreetBatchResponse(\x010\x01\x12N\n" +
+	"\vListLocales\x12\x1e.greeter.v1.ListLocalesRequest\x1a\x1f.greeter.v1.ListLocalesResponseB0Z.github.com/zhangbaodong/test/greetgrpc/greetpbb\x06proto3"
+
+var (
+	file_greetpb_greeter_proto_rawDescOnce sync.Once
+	file_greetpb_greeter_proto_rawDescData []byte
+)
+
+func file_greetpb_greeter_proto_rawDescGZIP() []byte {
+	file_greetpb_greeter_proto_rawDescOnce.Do(func() {
+		file_greetpb_greeter_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_greetpb_greeter_proto_rawDesc), len(file_greetpb_greeter_proto_rawDesc)))
+	})
+	return file_greetpb_greeter_proto_rawDescData
+}
+
+var file_greetpb_greeter_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_greetpb_greeter_proto_goTypes = []any{
+	(*GreetRequest)(nil),        // 0: greeter.v1.GreetRequest
+	(*GreetResponse)(nil),       // 1: greeter.v1.GreetResponse
+	(*GreetBatchResponse)(nil),  // 2: greeter.v1.GreetBatchResponse
+	(*ListLocalesRequest)(nil),  // 3: greeter.v1.ListLocalesRequest
+	(*ListLocalesResponse)(nil), // 4: greeter.v1.ListLocalesResponse
+}
+var file_greetpb_greeter_proto_depIdxs = []int32{
+	1, // 0: greeter.v1.GreetBatchResponse.result:type_name -> greeter.v1.GreetResponse
+	0, // 1: greeter.v1.GreeterService.Greet:input_type -> greeter.v1.GreetRequest
+	0, // 2: greeter.v1.GreeterService.GreetBatch:input_type -> greeter.v1.GreetRequest
+	3, // 3: greeter.v1.GreeterService.ListLocales:input_type -> greeter.v1.ListLocalesRequest
+	1, // 4: greeter.v1.GreeterService.Greet:output_type -> greeter.v1.GreetResponse
+	2, // 5: greeter.v1.GreeterService.GreetBatch:output_type -> greeter.v1.GreetBatchResponse
+	4, // 6: greeter.v1.GreeterService.ListLocales:output_type -> greeter.v1.ListLocalesResponse
+	4, // [4:7] is the sub-list for method output_type
+	1, // [1:4] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_greetpb_greeter_proto_init() }
+func file_greetpb_greeter_proto_init() {
+	if File_greetpb_greeter_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_greetpb_greeter_proto_rawDesc), len(file_greetpb_greeter_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_greetpb_greeter_proto_goTypes,
+		DependencyIndexes: file_greetpb_greeter_proto_depIdxs,
+		MessageInfos:      file_greetpb_greeter_proto_msgTypes,
+	}.Build()
+	File_greetpb_greeter_proto = out.File
+	file_greetpb_greeter_proto_goTypes = nil
+	file_greetpb_greeter_proto_depIdxs = nil
+}
@@ -0,0 +1,208 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: greetpb/greeter.proto
+
+package greetpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	GreeterService_Greet_FullMethodName       = "/greeter.v1.GreeterService/Greet"
+	GreeterService_GreetBatch_FullMethodName  = "/greeter.v1.GreeterService/GreetBatch"
+	GreeterService_ListLocales_FullMethodName = "/greeter.v1.GreeterService/ListLocales"
+)
+
+// GreeterServiceClient is the client API for GreeterService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// GreeterService generates greetings.
+type GreeterServiceClient interface {
+	// Greet greets a single name. Invalid names and unknown locales fail with
+	// INVALID_ARGUMENT.
+	Greet(ctx context.Context, in *GreetRequest, opts ...grpc.CallOption) (*GreetResponse, error)
+	// GreetBatch greets every name sent on the stream and answers each one, in
+	// order. A record that cannot be greeted is answered with its error and
+	// does not end the stream.
+	GreetBatch(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[GreetRequest, GreetBatchResponse], error)
+	// ListLocales lists the locales the server's catalog provides.
+	ListLocales(ctx context.Context, in *ListLocalesRequest, opts ...grpc.CallOption) (*ListLocalesResponse, error)
+}
+
+type greeterServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGreeterServiceClient(cc grpc.ClientConnInterface) GreeterServiceClient {
+	return &greeterServiceClient{cc}
+}
+
+func (c *greeterServiceClient) Greet(ctx context.Context, in *GreetRequest, opts ...grpc.CallOption) (*GreetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GreetResponse)
+	err := c.cc.Invoke(ctx, GreeterService_Greet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *greeterServiceClient) GreetBatch(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[GreetRequest, GreetBatchResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GreeterService_ServiceDesc.Streams[0], GreeterService_GreetBatch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GreetRequest, GreetBatchResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GreeterService_GreetBatchClient = grpc.BidiStreamingClient[GreetRequest, GreetBatchResponse]
+
+func (c *greeterServiceClient) ListLocales(ctx context.Context, in *ListLocalesRequest, opts ...grpc.CallOption) (*ListLocalesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLocalesResponse)
+	err := c.cc.Invoke(ctx, GreeterService_ListLocales_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GreeterServiceServer is the server API for GreeterService service.
+// All implementations must embed UnimplementedGreeterServiceServer
+// for forward compatibility.
+//
+// GreeterService generates greetings.
+type GreeterServiceServer interface {
+	// Greet greets a single name. Invalid names and unknown locales fail with
+	// INVALID_ARGUMENT.
+	Greet(context.Context, *GreetRequest) (*GreetResponse, error)
+	// GreetBatch greets every name sent on the stream and answers each one, in
+	// order. A record that cannot be greeted is answered with its error and
+	// does not end the stream.
+	GreetBatch(grpc.BidiStreamingServer[GreetRequest, GreetBatchResponse]) error
+	// ListLocales lists the locales the server's catalog provides.
+	ListLocales(context.Context, *ListLocalesRequest) (*ListLocalesResponse, error)
+	mustEmbedUnimplementedGreeterServiceServer()
+}
+
+// UnimplementedGreeterServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGreeterServiceServer struct{}
+
+func (UnimplementedGreeterServiceServer) Greet(context.Context, *GreetRequest) (*GreetResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Greet not implemented")
+}
+func (UnimplementedGreeterServiceServer) GreetBatch(grpc.BidiStreamingServer[GreetRequest, GreetBatchResponse]) error {
+	return status.Error(codes.Unimplemented, "method GreetBatch not implemented")
+}
+func (UnimplementedGreeterServiceServer) ListLocales(context.Context, *ListLocalesRequest) (*ListLocalesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListLocales not implemented")
+}
+func (UnimplementedGreeterServiceServer) mustEmbedUnimplementedGreeterServiceServer() {}
+func (UnimplementedGreeterServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeGreeterServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GreeterServiceServer will
+// result in compilation errors.
+type UnsafeGreeterServiceServer interface {
+	mustEmbedUnimplementedGreeterServiceServer()
+}
+
+func RegisterGreeterServiceServer(s grpc.ServiceRegistrar, srv GreeterServiceServer) {
+	// If the following call panics, it indicates UnimplementedGreeterServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&GreeterService_ServiceDesc, srv)
+}
+
+func _GreeterService_Greet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GreetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServiceServer).Greet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GreeterService_Greet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServiceServer).Greet(ctx, req.(*GreetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GreeterService_GreetBatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GreeterServiceServer).GreetBatch(&grpc.GenericServerStream[GreetRequest, GreetBatchResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GreeterService_GreetBatchServer = grpc.BidiStreamingServer[GreetRequest, GreetBatchResponse]
+
+func _GreeterService_ListLocales_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLocalesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServiceServer).ListLocales(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GreeterService_ListLocales_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServiceServer).ListLocales(ctx, req.(*ListLocalesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GreeterService_ServiceDesc is the grpc.ServiceDesc for GreeterService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GreeterService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "greeter.v1.GreeterService",
+	HandlerType: (*GreeterServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Greet",
+			Handler:    _GreeterService_Greet_Handler,
+		},
+		{
+			MethodName: "ListLocales",
+			Handler:    _GreeterService_ListLocales_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GreetBatch",
+			Handler:       _GreeterService_GreetBatch_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "greetpb/greeter.proto",
+}
@@ -0,0 +1,52 @@
+package greetgrpc
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/zhangbaodong/test/greetgrpc/greetpb"
+)
+
+// inProcessBuffer is the size of the in-memory connection buffer.
+const inProcessBuffer = 256 << 10
+
+// NewInProcessClient serves srv on an in-memory listener and returns a
+// client connected to it, so tests can exercise the full gRPC stack without
+// opening a network port. The returned function closes the client and stops
+// the server.
+//
+// Example:
+//
+//	client, stop, err := greetgrpc.NewInProcessClient(srv)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer stop()
+//	resp, err := client.Greet(ctx, &greetpb.GreetRequest{Name: "Alice"})
+func NewInProcessClient(srv greetpb.GreeterServiceServer, opts ...grpc.ServerOption) (greetpb.GreeterServiceClient, func(), error) {
+	lis := bufconn.Listen(inProcessBuffer)
+	s := grpc.NewServer(opts...)
+	greetpb.RegisterGreeterServiceServer(s, srv)
+	go s.Serve(lis)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		s.Stop()
+		return nil, nil, err
+	}
+
+	stop := func() {
+		conn.Close()
+		s.Stop()
+	}
+	return greetpb.NewGreeterServiceClient(conn), stop, nil
+}
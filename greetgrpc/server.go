@@ -0,0 +1,151 @@
+// Package greetgrpc serves greetings over gRPC.
+//
+// The service is defined in greetpb/greeter.proto; regenerate the Go code
+// in greetpb after editing it (see greetpb/generate.go). The package lives
+// in its own module so the core library does not depend on gRPC.
+//
+//	srv, err := greetgrpc.NewServer()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	s := grpc.NewServer()
+//	greetpb.RegisterGreeterServiceServer(s, srv)
+//	log.Fatal(s.Serve(lis))
+package greetgrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zhangbaodong/test"
+	"github.com/zhangbaodong/test/greetgrpc/greetpb"
+)
+
+// Option configures a Server.
+type Option func(*serverConfig)
+
+type serverConfig struct {
+	catalog *test.Catalog
+}
+
+// WithCatalog serves the locales of c instead of the built-in catalog.
+// c must not be modified after NewServer returns.
+func WithCatalog(c *test.Catalog) Option {
+	return func(cfg *serverConfig) {
+		cfg.catalog = c
+	}
+}
+
+// Server implements greetpb.GreeterServiceServer on top of the greeting
+// library. Names are normalized and validated like SayHiStrict; locales
+// fall back like NewGreeter.
+//
+// Thread Safety:
+//   A Server is immutable and safe for concurrent use by multiple goroutines.
+type Server struct {
+	greetpb.UnimplementedGreeterServiceServer
+
+	locales  []string
+	greeters map[string]*test.LocaleGreeter
+}
+
+// NewServer returns a Server with a greeter for every locale in the catalog.
+// An error is returned if one of them cannot be built.
+func NewServer(opts ...Option) (*Server, error) {
+	cfg := serverConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.catalog == nil {
+		cfg.catalog = test.DefaultCatalog()
+	}
+
+	s := &Server{
+		locales:  cfg.catalog.Locales(),
+		greeters: make(map[string]*test.LocaleGreeter),
+	}
+	for _, tag := range s.locales {
+		g, err := test.NewGreeter(tag, test.WithCatalog(cfg.catalog))
+		if err != nil {
+			return nil, fmt.Errorf("greetgrpc: locale %s: %w", tag, err)
+		}
+		s.greeters[tag] = g
+	}
+	return s, nil
+}
+
+// Greet implements greetpb.GreeterServiceServer.
+func (s *Server) Greet(ctx context.Context, req *greetpb.GreetRequest) (*greetpb.GreetResponse, error) {
+	resp, err := s.greet(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return resp, nil
+}
+
+// GreetBatch implements greetpb.GreeterServiceServer. Records are answered
+// in the order they arrive; the stream ends when the client closes its side.
+func (s *Server) GreetBatch(stream greetpb.GreeterService_GreetBatchServer) error {
+	for index := int64(0); ; index++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		out := &greetpb.GreetBatchResponse{Index: index}
+		if out.Result, err = s.greet(req); err != nil {
+			out.Error = err.Error()
+		}
+		if err := stream.Send(out); err != nil {
+			return err
+		}
+	}
+}
+
+// ListLocales implements greetpb.GreeterServiceServer.
+func (s *Server) ListLocales(ctx context.Context, req *greetpb.ListLocalesRequest) (*greetpb.ListLocalesResponse, error) {
+	return &greetpb.ListLocalesResponse{Locales: append([]string(nil), s.locales...)}, nil
+}
+
+// greet greets one request with the greeter for its locale.
+func (s *Server) greet(req *greetpb.GreetRequest) (*greetpb.GreetResponse, error) {
+	g, err := s.greeter(req.GetLocale())
+	if err != nil {
+		return nil, err
+	}
+	greeting, err := g.SayHiStrict(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return &greetpb.GreetResponse{
+		Greeting: greeting,
+		Name:     test.NormalizeName(req.GetName()),
+		Locale:   g.Locale(),
+	}, nil
+}
+
+// greeter returns the greeter for the first locale of tag's fallback chain
+// that the catalog provides. An empty tag means DefaultLocale.
+func (s *Server) greeter(tag string) (*test.LocaleGreeter, error) {
+	if tag == "" {
+		tag = test.DefaultLocale
+	}
+	canon, err := test.CanonicalTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range test.FallbackChain(canon) {
+		if g, ok := s.greeters[t]; ok {
+			return g, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported locale %q", tag)
+}
@@ -0,0 +1,140 @@
+package greetgrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zhangbaodong/test"
+	"github.com/zhangbaodong/test/greetgrpc/greetpb"
+)
+
+// newClient starts a Server with the built-in catalog on bufconn
+func newClient(t *testing.T) greetpb.GreeterServiceClient {
+	t.Helper()
+	srv, err := NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, stop, err := NewInProcessClient(srv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(stop)
+	return client
+}
+
+// TestServerGreet tests unary greetings across locales
+func TestServerGreet(t *testing.T) {
+	tests := []struct {
+		name     string
+		locale   string
+		greeting string
+		resolved string
+	}{
+		{"Alice", "", "Hi, Alice", "en"},
+		{"  Bob  ", "en", "Hi, Bob", "en"},
+		{"Alice", "pt_br", "Oi, Alice", "pt-BR"},
+		{"Alice", "de-AT", "Hallo, Alice", "de"},
+		{"Alice", "xx", "Hi, Alice", "en"},
+	}
+
+	client := newClient(t)
+	for _, tt := range tests {
+		resp, err := client.Greet(context.Background(), &greetpb.GreetRequest{Name: tt.name, Locale: tt.locale})
+		if err != nil {
+			t.Errorf("Greet(%q, %q) error: %v", tt.name, tt.locale, err)
+			continue
+		}
+		if resp.GetGreeting() != tt.greeting || resp.GetLocale() != tt.resolved || resp.GetName() != test.NormalizeName(tt.name) {
+			t.Errorf("Greet(%q, %q) = %v, want %q from %s", tt.name, tt.locale, resp, tt.greeting, tt.resolved)
+		}
+	}
+}
+
+// TestServerGreetInvalid tests that bad input fails with INVALID_ARGUMENT
+func TestServerGreetInvalid(t *testing.T) {
+	client := newClient(t)
+	for _, req := range []*greetpb.GreetRequest{
+		{Name: ""},
+		{Name: "Eve\x00"},
+		{Name: "Alice", Locale: "not a tag"},
+	} {
+		_, err := client.Greet(context.Background(), req)
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Greet(%v) error = %v, want InvalidArgument", req, err)
+		}
+	}
+}
+
+// TestServerGreetBatch tests ordered streaming with per-record errors
+func TestServerGreetBatch(t *testing.T) {
+	stream, err := newClient(t).GreetBatch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{"Alice", "", "Bob", "Charlie"}
+	go func() {
+		for _, name := range names {
+			stream.Send(&greetpb.GreetRequest{Name: name, Locale: "es"})
+		}
+		stream.CloseSend()
+	}()
+
+	var got []string
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.GetIndex() != int64(len(got)) {
+			t.Fatalf("response index %d, want %d", resp.GetIndex(), len(got))
+		}
+		if resp.GetError() != "" {
+			got = append(got, "error")
+			continue
+		}
+		got = append(got, resp.GetResult().GetGreeting())
+	}
+
+	want := []string{"Hola, Alice", "error", "Hola, Bob", "Hola, Charlie"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GreetBatch = %q, want %q", got, want)
+	}
+}
+
+// TestServerListLocales tests locale listing for the built-in and custom catalogs
+func TestServerListLocales(t *testing.T) {
+	resp, err := newClient(t).ListLocales(context.Background(), &greetpb.ListLocalesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := test.DefaultCatalog().Locales(); !reflect.DeepEqual(resp.GetLocales(), want) {
+		t.Errorf("ListLocales = %q, want %q", resp.GetLocales(), want)
+	}
+
+	c := test.NewCatalog()
+	if err := c.Set("en", test.MessageGreeting, "Hello, {name}"); err != nil {
+		t.Fatal(err)
+	}
+	srv, err := NewServer(WithCatalog(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	custom, _ := srv.ListLocales(context.Background(), &greetpb.ListLocalesRequest{})
+	if !reflect.DeepEqual(custom.GetLocales(), []string{"en"}) {
+		t.Errorf("custom ListLocales = %q, want [en]", custom.GetLocales())
+	}
+	if resp, err := srv.Greet(context.Background(), &greetpb.GreetRequest{Name: "Alice", Locale: "fr"}); err != nil || resp.GetGreeting() != "Hello, Alice" {
+		t.Errorf("custom Greet = %v, %v", resp, err)
+	}
+}
@@ -0,0 +1,336 @@
+package greethttp
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DefaultMinCompressSize is the smallest response body Compress compresses.
+// Below about a kilobyte the encoding overhead outweighs the savings; a
+// greeting is a few dozen bytes.
+const DefaultMinCompressSize = 1024
+
+// Compressor is a resettable compressing writer, as implemented by
+// gzip.Writer and flate.Writer, and by the Brotli and zstd writers the
+// greetcompress module wraps.
+type Compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// Encoding is a content coding Compress can produce.
+type Encoding struct {
+	Name      string                         // coding token, e.g. "br"
+	NewWriter func(w io.Writer) Compressor // returns a writer compressing into w
+}
+
+// Gzip returns the "gzip" coding at the given compress/gzip level.
+// It panics if level is invalid.
+func Gzip(level int) Encoding {
+	if _, err := gzip.NewWriterLevel(io.Discard, level); err != nil {
+		panic("greethttp: " + err.Error())
+	}
+	return Encoding{Name: "gzip", NewWriter: func(w io.Writer) Compressor {
+		zw, _ := gzip.NewWriterLevel(w, level)
+		return zw
+	}}
+}
+
+// Deflate returns the "deflate" coding at the given compress/flate level.
+// It panics if level is invalid.
+func Deflate(level int) Encoding {
+	if _, err := flate.NewWriter(io.Discard, level); err != nil {
+		panic("greethttp: " + err.Error())
+	}
+	return Encoding{Name: "deflate", NewWriter: func(w io.Writer) Compressor {
+		zw, _ := flate.NewWriter(w, level)
+		return zw
+	}}
+}
+
+// CompressOption configures Compress.
+type CompressOption func(*compressor)
+
+// WithEncodings sets the codings Compress offers, most preferred first;
+// the preference breaks ties between equal q-values. The default is
+// Gzip(gzip.DefaultCompression) then Deflate(flate.DefaultCompression).
+//
+// Brotli and zstd are in the greetcompress module, which keeps this one on
+// the standard library:
+//
+//	h := greethttp.Compress(next, greethttp.WithEncodings(
+//		greetcompress.Brotli(brotli.DefaultCompression),
+//		greethttp.Gzip(gzip.DefaultCompression)))
+func WithEncodings(encs ...Encoding) CompressOption {
+	return func(c *compressor) {
+		c.encodings = encs
+	}
+}
+
+// WithMinCompressSize compresses only response bodies of at least n bytes.
+// The default is DefaultMinCompressSize.
+func WithMinCompressSize(n int) CompressOption {
+	return func(c *compressor) {
+		c.minSize = n
+	}
+}
+
+// Compress wraps next with response compression negotiated by the
+// Accept-Encoding header, honoring q-values and "identity;q=0". Responses
+// are compressed only if they have at least the minimum size, a textual
+// Content-Type, a status with a body and no Content-Encoding of their own;
+// a compressed response loses its Content-Length and its ETag becomes weak.
+// Every response carries "Vary: Accept-Encoding". Writers are pooled per
+// coding.
+//
+// Example:
+//
+//	h := greethttp.Compress(greethttp.New(), greethttp.WithMinCompressSize(256))
+//
+// Thread Safety:
+//   The returned handler is safe for concurrent use by multiple goroutines.
+func Compress(next http.Handler, opts ...CompressOption) http.Handler {
+	c := &compressor{
+		next:      next,
+		minSize:   DefaultMinCompressSize,
+		encodings: []Encoding{Gzip(gzip.DefaultCompression), Deflate(flate.DefaultCompression)},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.pools = make(map[string]*sync.Pool, len(c.encodings))
+	for _, enc := range c.encodings {
+		name := strings.ToLower(enc.Name)
+		newWriter := enc.NewWriter
+		c.names = append(c.names, name)
+		c.pools[name] = &sync.Pool{New: func() interface{} {
+			return newWriter(io.Discard)
+		}}
+	}
+	return c
+}
+
+type compressor struct {
+	next      http.Handler
+	minSize   int
+	encodings []Encoding
+	names     []string
+	pools     map[string]*sync.Pool
+}
+
+// ServeHTTP implements http.Handler.
+func (c *compressor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	addVary(w.Header(), "Accept-Encoding")
+	coding := negotiateEncoding(r.Header.Get("Accept-Encoding"), c.names)
+	if coding == "" || r.Method == http.MethodHead {
+		c.next.ServeHTTP(w, r)
+		return
+	}
+
+	cw := &compressWriter{ResponseWriter: w, coding: coding, pool: c.pools[coding], minSize: c.minSize}
+	defer cw.close()
+	c.next.ServeHTTP(cw, r)
+}
+
+// compressWriter buffers the start of a response until it knows whether
+// the body reaches the minimum size, then commits to compressing it or not.
+type compressWriter struct {
+	http.ResponseWriter
+	coding  string
+	pool    *sync.Pool
+	minSize int
+
+	status  int
+	buf     []byte
+	decided bool
+	zw      Compressor // non-nil if compressing
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.decided || w.status != 0 {
+		return
+	}
+	if code < 200 {
+		// Informational responses pass straight through.
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+	if !compressibleStatus(code) {
+		w.decide(false)
+	}
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.decided {
+		if w.zw != nil {
+			return w.zw.Write(p)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+
+	w.buf = append(w.buf, p...)
+	if len(w.buf) < w.minSize {
+		return len(p), nil
+	}
+	if err := w.decide(true); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush implements http.Flusher. Flushing a response still being buffered
+// commits to compressing it.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(true)
+	}
+	if w.zw != nil {
+		w.zw.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decide writes the header, compressing the body if compress is true and
+// the response qualifies, and then the buffered body.
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	h := w.Header()
+	if compress && h.Get("Content-Encoding") == "" {
+		if h.Get("Content-Type") == "" {
+			// Sniff now: net/http would sniff the compressed bytes.
+			h.Set("Content-Type", http.DetectContentType(w.buf))
+		}
+		if compressibleType(h.Get("Content-Type")) {
+			h.Set("Content-Encoding", w.coding)
+			h.Del("Content-Length")
+			if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+				h.Set("ETag", "W/"+etag)
+			}
+			w.zw = w.pool.Get().(Compressor)
+			w.zw.Reset(w.ResponseWriter)
+		}
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.zw != nil {
+		_, err = w.zw.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// close finishes the response once the handler has returned.
+func (w *compressWriter) close() {
+	if !w.decided {
+		// The whole body is below the minimum size.
+		w.decide(false)
+	}
+	if w.zw != nil {
+		w.zw.Close()
+		w.zw.Reset(io.Discard)
+		w.pool.Put(w.zw)
+		w.zw = nil
+	}
+}
+
+// negotiateEncoding picks the content coding an Accept-Encoding header
+// prefers among offers, which are in the server's order of preference.
+// It returns "" for the identity coding, which is the answer for an absent
+// header and when no offer is acceptable. Identity is always acceptable
+// but only competes with the offers when the header lists it, directly or
+// as "*"; ties go to the offer.
+func negotiateEncoding(header string, offers []string) string {
+	if strings.TrimSpace(header) == "" {
+		return ""
+	}
+	ranges := parseAccept(header)
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := codingQuality(ranges, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	if best != "" && bestQ >= codingQuality(ranges, "identity") {
+		return best
+	}
+	return ""
+}
+
+// codingQuality returns the q-value an Accept-Encoding header gives coding,
+// or 0 if the header does not mention it.
+func codingQuality(ranges []acceptRange, coding string) float64 {
+	q := 0.0
+	for _, r := range ranges {
+		switch {
+		case r.value == coding, coding == "gzip" && r.value == "x-gzip":
+			return r.q
+		case r.value == "*":
+			q = r.q
+		}
+	}
+	return q
+}
+
+// compressibleStatus reports whether responses with code carry a body
+// worth compressing. Partial content is excluded because its byte ranges
+// refer to the uncompressed representation.
+func compressibleStatus(code int) bool {
+	return code != http.StatusNoContent && code != http.StatusNotModified && code != http.StatusPartialContent
+}
+
+// compressibleType reports whether a Content-Type is textual. Images,
+// archives and other already compressed formats are left alone.
+func compressibleType(contentType string) bool {
+	mt, _, _ := strings.Cut(contentType, ";")
+	mt = strings.ToLower(strings.TrimSpace(mt))
+	switch {
+	case strings.HasPrefix(mt, "text/"),
+		strings.HasSuffix(mt, "+json"),
+		strings.HasSuffix(mt, "+xml"):
+		return true
+	}
+	switch mt {
+	case "application/json", "application/javascript", "application/xml", "application/wasm":
+		return true
+	}
+	return false
+}
+
+// addVary adds field to the Vary header unless it is already listed.
+func addVary(h http.Header, field string) {
+	for _, v := range h.Values("Vary") {
+		for _, f := range strings.Split(v, ",") {
+			if f = strings.TrimSpace(f); f == "*" || strings.EqualFold(f, field) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", field)
+}
@@ -0,0 +1,202 @@
+package greethttp
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestNegotiateEncoding tests Accept-Encoding negotiation with q-values
+func TestNegotiateEncoding(t *testing.T) {
+	offers := []string{"br", "gzip", "deflate"}
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"GZIP", "gzip"},
+		{"x-gzip", "gzip"},
+		{"gzip, deflate, br", "br"},
+		{"gzip;q=1, br;q=0.5", "gzip"},
+		{"deflate;q=0.9, gzip;q=0.8", "deflate"},
+		{"*", "br"},
+		{"*;q=0.5, br;q=0", "gzip"},
+		{"gzip;q=0", ""},
+		{"identity", ""},
+		{"gzip;q=0.5, identity;q=0.8", ""},
+		{"gzip;q=0.8, identity;q=0.8", "gzip"},
+		{"gzip;q=0.1, identity;q=0", "gzip"},
+		{"*;q=0", ""},
+		{"compress, zstd", ""},
+		{"gzip;q=bogus", ""},
+	}
+
+	for _, tt := range tests {
+		if result := negotiateEncoding(tt.header, offers); result != tt.expected {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.header, result, tt.expected)
+		}
+	}
+}
+
+// bodyHandler writes body with the given content type and status
+func bodyHandler(status int, contentType, body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		w.Header().Set("Content-Length", "999")
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(status)
+		// Write in pieces to exercise buffering.
+		for rest := body; len(rest) > 0; {
+			n := 100
+			if n > len(rest) {
+				n = len(rest)
+			}
+			io.WriteString(w, rest[:n])
+			rest = rest[n:]
+		}
+	})
+}
+
+// decode returns the decompressed body of a response
+func decode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var r io.Reader = w.Body
+	switch w.Header().Get("Content-Encoding") {
+	case "gzip":
+		zr, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = zr
+	case "deflate":
+		r = flate.NewReader(w.Body)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// TestCompress tests which responses are compressed and their headers
+func TestCompress(t *testing.T) {
+	big := strings.Repeat("Hi, Alice! ", 200)
+	tests := []struct {
+		name        string
+		handler     http.Handler
+		method      string
+		accept      string
+		encoding    string
+		contentType string
+	}{
+		{"gzip", bodyHandler(200, "text/plain", big), "GET", "gzip", "gzip", "text/plain"},
+		{"deflate", bodyHandler(200, "application/json", big), "GET", "deflate", "deflate", "application/json"},
+		{"preference", bodyHandler(200, "text/plain", big), "GET", "deflate, gzip", "gzip", "text/plain"},
+		{"sniffed", bodyHandler(200, "", big), "GET", "gzip", "gzip", "text/plain; charset=utf-8"},
+		{"small", bodyHandler(200, "text/plain", "Hi, Alice"), "GET", "gzip", "", "text/plain"},
+		{"no accept", bodyHandler(200, "text/plain", big), "GET", "", "", "text/plain"},
+		{"image", bodyHandler(200, "image/png", big), "GET", "gzip", "", "image/png"},
+		{"no content", bodyHandler(204, "text/plain", ""), "GET", "gzip", "", "text/plain"},
+		{"partial", bodyHandler(206, "text/plain", big), "GET", "gzip", "", "text/plain"},
+		{"head", bodyHandler(200, "text/plain", ""), "HEAD", "gzip", "", "text/plain"},
+		{"error", bodyHandler(500, "text/plain", big), "GET", "gzip", "gzip", "text/plain"},
+	}
+
+	for _, tt := range tests {
+		h := Compress(tt.handler)
+		r := httptest.NewRequest(tt.method, "/", nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept-Encoding", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		hdr := w.Header()
+		if got := hdr.Get("Content-Encoding"); got != tt.encoding {
+			t.Errorf("%s: Content-Encoding = %q, want %q", tt.name, got, tt.encoding)
+		}
+		if got := hdr.Get("Content-Type"); got != tt.contentType {
+			t.Errorf("%s: Content-Type = %q, want %q", tt.name, got, tt.contentType)
+		}
+		if got := hdr.Values("Vary"); len(got) != 1 || got[0] != "Accept-Encoding" {
+			t.Errorf("%s: Vary = %q", tt.name, got)
+		}
+		if tt.encoding == "" {
+			if hdr.Get("Content-Length") != "999" || hdr.Get("ETag") != `"v1"` {
+				t.Errorf("%s: uncompressed response lost Content-Length or ETag: %v", tt.name, hdr)
+			}
+			continue
+		}
+		if hdr.Get("Content-Length") != "" || hdr.Get("ETag") != `W/"v1"` {
+			t.Errorf("%s: Content-Length %q, ETag %q", tt.name, hdr.Get("Content-Length"), hdr.Get("ETag"))
+		}
+		if body := decode(t, w); body != big {
+			t.Errorf("%s: decoded body has %d bytes, want %d", tt.name, len(body), len(big))
+		}
+	}
+}
+
+// TestCompressOptions tests the size threshold, custom codings and writer reuse
+func TestCompressOptions(t *testing.T) {
+	custom := Encoding{Name: "x-test", NewWriter: func(w io.Writer) Compressor {
+		zw, _ := gzip.NewWriterLevel(w, gzip.BestSpeed)
+		return zw
+	}}
+	h := Compress(bodyHandler(200, "text/plain", "Hi, Alice"),
+		WithEncodings(custom, Gzip(gzip.BestCompression)), WithMinCompressSize(5))
+
+	// Repeated requests reuse pooled writers, which must be reset cleanly.
+	for i := 0; i < 3; i++ {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept-Encoding", "gzip, x-test")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if got := w.Header().Get("Content-Encoding"); got != "x-test" {
+			t.Fatalf("request %d: Content-Encoding = %q, want x-test", i, got)
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		if body := decode(t, w); body != "Hi, Alice" {
+			t.Errorf("request %d: body = %q", i, body)
+		}
+	}
+}
+
+// TestCompressHandler tests compression of the greeting handler and streaming
+func TestCompressHandler(t *testing.T) {
+	h := Compress(New(), WithMinCompressSize(1))
+	r := httptest.NewRequest("GET", "/api/greet?name=Alice", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if vary := strings.Join(w.Header().Values("Vary"), ", "); vary != "Accept-Encoding, Accept" {
+		t.Errorf("Vary = %q", vary)
+	}
+	if body := decode(t, w); !strings.Contains(body, `"greeting":"Hi, Alice"`) {
+		t.Errorf("body = %q", body)
+	}
+
+	stream := Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "first")
+		w.(http.Flusher).Flush()
+		io.WriteString(w, " second")
+	}))
+	r = httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	stream.ServeHTTP(w, r)
+	if !w.Flushed || w.Header().Get("Content-Encoding") != "gzip" {
+		t.Errorf("flushed %v, Content-Encoding %q", w.Flushed, w.Header().Get("Content-Encoding"))
+	}
+	if body := decode(t, w); body != "first second" {
+		t.Errorf("streamed body = %q", body)
+	}
+}
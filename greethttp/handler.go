@@ -0,0 +1,314 @@
+// Package greethttp serves greetings over HTTP.
+//
+// Handler exposes an HTML greeting page, a content-negotiated greeting API
+// and a health check, and can be mounted in any http.ServeMux:
+//
+//	mux := http.NewServeMux()
+//	mux.Handle("/hello/", http.StripPrefix("/hello", greethttp.New()))
+package greethttp
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zhangbaodong/test"
+)
+
+// DefaultName is greeted by the API when the request carries no name.
+const DefaultName = "Guest"
+
+// Media types served by Handler.
+const (
+	MediaJSON = "application/json"
+	MediaText = "text/plain"
+	MediaHTML = "text/html"
+)
+
+// Error codes reported in ErrorResponse.
+const (
+	CodeInvalidName      = "invalid_name"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeNotAcceptable    = "not_acceptable"
+	CodeRateLimited      = "rate_limited"
+)
+
+// apiOffers lists the representations of /api/greet, JSON first so clients
+// that accept anything get JSON.
+var apiOffers = []string{MediaJSON, MediaText, MediaHTML}
+
+// errorOffers lists the representations of an error response.
+var errorOffers = []string{MediaJSON, MediaText}
+
+// GreetingResponse is the JSON body of a successful /api/greet request.
+type GreetingResponse struct {
+	Greeting  string `json:"greeting"`
+	Name      string `json:"name"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// ErrorResponse is the JSON body of a failed request.
+type ErrorResponse struct {
+	Error ErrorDetail `json:"error"`
+}
+
+// ErrorDetail describes why a request failed. Code is one of the Code
+// constants and is stable; Message is meant for humans.
+type ErrorDetail struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Option configures a Handler.
+type Option func(*Handler)
+
+// WithDefaultName sets the name greeted when a request carries none.
+// The default is DefaultName.
+func WithDefaultName(name string) Option {
+	return func(h *Handler) {
+		h.defaultName = name
+	}
+}
+
+// WithGreeter greets with g, e.g. a *test.LocaleGreeter for another
+// language than DefaultLocale or a strategy from test.NewStrategy. Metrics
+// are labeled with the locale of greeters that have a Tag method.
+func WithGreeter(g test.Greeter) Option {
+	return func(h *Handler) {
+		h.greet = g.SayHiStrict
+		h.locale = ""
+		if t, ok := g.(interface{ Tag() string }); ok {
+			h.locale = t.Tag()
+		}
+	}
+}
+
+// WithGreetFunc greets with f, which must normalize and validate names the
+// way SayHiStrict does. Of WithGreeter and WithGreetFunc, the last wins.
+func WithGreetFunc(f test.GreetFunc) Option {
+	return func(h *Handler) {
+		h.greet = f
+		h.locale = ""
+	}
+}
+
+// WithMetrics records requests in m and serves m at /metrics.
+func WithMetrics(m *Metrics) Option {
+	return func(h *Handler) {
+		h.metrics = m
+	}
+}
+
+// WithBranding renders the HTML page with b's title, colors and
+// stylesheet.
+func WithBranding(b Branding) Option {
+	return func(h *Handler) {
+		h.brand = b.withDefaults()
+	}
+}
+
+// Handler is an http.Handler serving greetings. Names are normalized and
+// validated with SayHiStrict semantics; invalid names are rejected with a
+// 400 ErrorResponse rather than echoed back.
+//
+// Routes, relative to where the Handler is mounted:
+//
+//	GET /                 HTML greeting page
+//	GET /greet?name=...   HTML greeting page
+//	GET /api/greet?name=  greeting as JSON, text or HTML, chosen by Accept
+//	GET /api/simple?name= greeting as plain text
+//	GET /health           {"status":"ok"}
+//	GET /metrics          Prometheus metrics, with WithMetrics
+//
+// Example:
+//
+//	h := greethttp.New(greethttp.WithDefaultName("friend"))
+//	log.Fatal(http.ListenAndServe(":8080", h))
+//
+// Thread Safety:
+//   A Handler is immutable and safe for concurrent use by multiple goroutines.
+type Handler struct {
+	greet       test.GreetFunc
+	locale      string // metrics label; empty for a custom GreetFunc
+	defaultName string
+	metrics     *Metrics
+	brand       Branding
+	mux         *http.ServeMux
+}
+
+// routes are the paths served by Handler, used as the endpoint label of
+// metrics. Requests for other paths are labeled "other".
+var routes = map[string]bool{
+	"/": true, "/greet": true, "/api/greet": true, "/api/simple": true, "/health": true, "/metrics": true,
+}
+
+// New returns a Handler configured by opts.
+func New(opts ...Option) *Handler {
+	h := &Handler{
+		greet:       test.SayHiStrict,
+		locale:      test.DefaultLocale,
+		defaultName: DefaultName,
+		brand:       Branding{}.withDefaults(),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	h.mux = http.NewServeMux()
+	h.mux.HandleFunc("/", h.serveRoot)
+	h.mux.HandleFunc("/greet", h.servePage)
+	h.mux.HandleFunc("/api/greet", h.serveGreet)
+	h.mux.HandleFunc("/api/simple", h.serveSimple)
+	h.mux.HandleFunc("/health", h.serveHealth)
+	if h.metrics != nil {
+		h.mux.Handle("/metrics", h.metrics)
+	}
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.metrics != nil {
+		endpoint := r.URL.Path
+		if !routes[endpoint] {
+			endpoint = "other"
+		}
+		rec := &statusRecorder{ResponseWriter: w}
+		done := h.metrics.begin(endpoint, h.locale)
+		defer func() { done(rec.code()) }()
+		w = rec
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "method "+r.Method+" is not allowed")
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// serveRoot serves the page at "/" and reports every other unmatched path
+// as not found.
+func (h *Handler) serveRoot(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" && r.URL.Path != "" {
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "no route for "+r.URL.Path)
+		return
+	}
+	h.servePage(w, r)
+}
+
+// servePage renders the HTML form, with a greeting if a name was submitted.
+func (h *Handler) servePage(w http.ResponseWriter, r *http.Request) {
+	data := pageData{Name: r.URL.Query().Get("name"), DefaultName: h.defaultName}
+	status := http.StatusOK
+	if strings.TrimSpace(data.Name) != "" {
+		greeting, err := h.greet(data.Name)
+		if err != nil {
+			h.validationFailed(err)
+			status = http.StatusBadRequest
+			data.Error = err.Error()
+		}
+		data.Greeting = greeting
+	}
+	h.writePage(w, status, data)
+}
+
+// serveGreet serves the greeting in the representation the client prefers.
+func (h *Handler) serveGreet(w http.ResponseWriter, r *http.Request) {
+	media := negotiate(r.Header.Get("Accept"), apiOffers)
+	if media == "" {
+		writeError(w, r, http.StatusNotAcceptable, CodeNotAcceptable,
+			"supported media types are "+strings.Join(apiOffers, ", "))
+		return
+	}
+	addVary(w.Header(), "Accept")
+
+	name, greeting, ok := h.greetRequest(w, r)
+	if !ok {
+		return
+	}
+	switch media {
+	case MediaJSON:
+		writeJSON(w, http.StatusOK, GreetingResponse{
+			Greeting:  greeting,
+			Name:      name,
+			Timestamp: time.Now().Unix(),
+		})
+	case MediaText:
+		writeText(w, http.StatusOK, greeting)
+	case MediaHTML:
+		h.writePage(w, http.StatusOK, pageData{Name: name, Greeting: greeting, DefaultName: h.defaultName})
+	}
+}
+
+// serveSimple serves the greeting as plain text.
+func (h *Handler) serveSimple(w http.ResponseWriter, r *http.Request) {
+	if _, greeting, ok := h.greetRequest(w, r); ok {
+		writeText(w, http.StatusOK, greeting)
+	}
+}
+
+// serveHealth reports that the handler is up.
+func (h *Handler) serveHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// greetRequest greets the name in the request's query, or the default name.
+// On failure it writes an error response and reports false.
+func (h *Handler) greetRequest(w http.ResponseWriter, r *http.Request) (name, greeting string, ok bool) {
+	name = r.URL.Query().Get("name")
+	if strings.TrimSpace(name) == "" {
+		name = h.defaultName
+	}
+
+	greeting, err := h.greet(name)
+	if err != nil {
+		h.validationFailed(err)
+		writeError(w, r, http.StatusBadRequest, CodeInvalidName, err.Error())
+		return "", "", false
+	}
+	return test.NormalizeName(name), greeting, true
+}
+
+// validationFailed counts a rejected name if metrics are enabled.
+func (h *Handler) validationFailed(err error) {
+	if h.metrics != nil {
+		h.metrics.validationFailed(err)
+	}
+}
+
+// writeError writes a structured error as JSON, or as text to clients that
+// prefer it. Errors are never refused for want of an acceptable type.
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, msg string) {
+	if negotiate(r.Header.Get("Accept"), errorOffers) == MediaText {
+		writeText(w, status, code+": "+msg)
+		return
+	}
+	writeJSON(w, status, ErrorResponse{Error: ErrorDetail{Status: status, Code: code, Message: msg}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", MediaJSON+"; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeText(w http.ResponseWriter, status int, s string) {
+	w.Header().Set("Content-Type", MediaText+"; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	w.Write([]byte(s))
+}
+
+// writePage renders the HTML page with the handler's branding.
+func (h *Handler) writePage(w http.ResponseWriter, status int, data pageData) {
+	data.Brand = h.brand
+	data.Stylesheet = template.CSS(h.brand.Stylesheet)
+	w.Header().Set("Content-Type", MediaHTML+"; charset=utf-8")
+	w.WriteHeader(status)
+	pageTemplate.Execute(w, data)
+}
@@ -0,0 +1,195 @@
+package greethttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zhangbaodong/test"
+)
+
+// serve runs one request through h and returns the recorded response
+func serve(h http.Handler, method, target, accept string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, nil)
+	if accept != "" {
+		r.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// TestHandlerGreetJSON tests JSON encoding of the greeting API
+func TestHandlerGreetJSON(t *testing.T) {
+	tests := []struct {
+		target   string
+		name     string
+		greeting string
+	}{
+		{"/api/greet?name=Alice", "Alice", "Hi, Alice"},
+		{"/api/greet", "Guest", "Hi, Guest"},
+		{"/api/greet?name=%22quoted%22+%5Cname", `"quoted" \name`, `Hi, "quoted" \name`},
+		{"/api/greet?name=++Bob++", "Bob", "Hi, Bob"},
+	}
+
+	h := New()
+	for _, tt := range tests {
+		w := serve(h, http.MethodGet, tt.target, "")
+		if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), MediaJSON) {
+			t.Errorf("GET %s = %d %s", tt.target, w.Code, w.Header().Get("Content-Type"))
+			continue
+		}
+		var resp GreetingResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Errorf("GET %s: invalid JSON %q: %v", tt.target, w.Body.String(), err)
+			continue
+		}
+		if resp.Name != tt.name || resp.Greeting != tt.greeting {
+			t.Errorf("GET %s = %+v, want name %q greeting %q", tt.target, resp, tt.name, tt.greeting)
+		}
+	}
+}
+
+// TestHandlerNegotiation tests Accept-based content negotiation
+func TestHandlerNegotiation(t *testing.T) {
+	tests := []struct {
+		accept string
+		status int
+		media  string
+	}{
+		{"", http.StatusOK, MediaJSON},
+		{"*/*", http.StatusOK, MediaJSON},
+		{"text/plain", http.StatusOK, MediaText},
+		{"text/*", http.StatusOK, MediaText},
+		{"text/html,application/xhtml+xml,*/*;q=0.8", http.StatusOK, MediaHTML},
+		{"application/json;q=0.5, text/plain;q=0.9", http.StatusOK, MediaText},
+		{"text/plain;q=0, */*", http.StatusOK, MediaJSON},
+		{"image/png", http.StatusNotAcceptable, MediaJSON},
+	}
+
+	h := New()
+	for _, tt := range tests {
+		w := serve(h, http.MethodGet, "/api/greet?name=Alice", tt.accept)
+		if w.Code != tt.status || !strings.HasPrefix(w.Header().Get("Content-Type"), tt.media) {
+			t.Errorf("Accept %q = %d %s, want %d %s", tt.accept, w.Code, w.Header().Get("Content-Type"), tt.status, tt.media)
+		}
+	}
+}
+
+// TestHandlerErrors tests structured error responses
+func TestHandlerErrors(t *testing.T) {
+	tests := []struct {
+		method string
+		target string
+		status int
+		code   string
+	}{
+		{http.MethodGet, "/api/greet?name=Eve%00", http.StatusBadRequest, CodeInvalidName},
+		{http.MethodGet, "/api/simple?name=" + strings.Repeat("x", test.MaxNameLength+1), http.StatusBadRequest, CodeInvalidName},
+		{http.MethodPost, "/api/greet", http.StatusMethodNotAllowed, CodeMethodNotAllowed},
+		{http.MethodGet, "/missing", http.StatusNotFound, CodeNotFound},
+	}
+
+	h := New()
+	for _, tt := range tests {
+		w := serve(h, tt.method, tt.target, "")
+		var resp ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Errorf("%s %s: invalid JSON %q: %v", tt.method, tt.target, w.Body.String(), err)
+			continue
+		}
+		if w.Code != tt.status || resp.Error.Status != tt.status || resp.Error.Code != tt.code || resp.Error.Message == "" {
+			t.Errorf("%s %s = %d %+v, want %d %s", tt.method, tt.target, w.Code, resp, tt.status, tt.code)
+		}
+	}
+
+	w := serve(h, http.MethodGet, "/api/simple?name=Eve%00", "text/plain")
+	if w.Code != http.StatusBadRequest || !strings.HasPrefix(w.Body.String(), CodeInvalidName+": ") {
+		t.Errorf("text error = %d %q", w.Code, w.Body.String())
+	}
+}
+
+// TestHandlerPage tests the HTML page and its escaping
+func TestHandlerPage(t *testing.T) {
+	h := New(WithDefaultName("friend"))
+
+	w := serve(h, http.MethodGet, "/greet?name=%3Cscript%3E", "")
+	body := w.Body.String()
+	if w.Code != http.StatusOK || strings.Contains(body, "<script>") || !strings.Contains(body, "Hi, &lt;script&gt;") {
+		t.Errorf("page did not escape the name: %d %q", w.Code, body)
+	}
+	if !strings.Contains(body, "defaults to friend") {
+		t.Error("page does not mention the configured default name")
+	}
+
+	if w := serve(h, http.MethodGet, "/", ""); w.Code != http.StatusOK || strings.Contains(w.Body.String(), `class="greeting"`) {
+		t.Errorf("empty page = %d, want no greeting", w.Code)
+	}
+}
+
+// TestHandlerOptions tests the configurable name and greeter
+func TestHandlerOptions(t *testing.T) {
+	g, err := test.NewGreeter("pt-BR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := New(WithGreeter(g), WithDefaultName("amigo"))
+
+	if w := serve(h, http.MethodGet, "/api/simple", ""); w.Body.String() != "Oi, amigo" {
+		t.Errorf("simple = %q, want %q", w.Body.String(), "Oi, amigo")
+	}
+	if w := serve(h, http.MethodGet, "/health", ""); w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"status":"ok"}` {
+		t.Errorf("health = %d %q", w.Code, w.Body.String())
+	}
+}
+
+// TestHandlerStrategy tests serving a decorated greeting strategy
+func TestHandlerStrategy(t *testing.T) {
+	g, err := test.NewStrategy(test.StrategyFormal, "de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var audited []string
+	h := New(WithGreeter(test.Chain(g, test.Auditing(func(r test.AuditRecord) {
+		audited = append(audited, r.Greeting)
+	}))))
+
+	if w := serve(h, http.MethodGet, "/api/simple?name=Anna", ""); w.Body.String() != "Guten Tag, Anna" {
+		t.Errorf("simple = %q", w.Body.String())
+	}
+	if len(audited) != 1 || audited[0] != "Guten Tag, Anna" {
+		t.Errorf("audited %q", audited)
+	}
+}
+
+// TestHandlerMount tests mounting the handler under a prefix
+func TestHandlerMount(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/hello/", http.StripPrefix("/hello", New()))
+
+	if w := serve(mux, http.MethodGet, "/hello/api/simple?name=Alice", ""); w.Body.String() != "Hi, Alice" {
+		t.Errorf("mounted simple = %d %q", w.Code, w.Body.String())
+	}
+}
+
+// TestHandlerBranding tests the customizable page title, colors and styles
+func TestHandlerBranding(t *testing.T) {
+	h := New(WithBranding(Branding{Title: "ACME <Greeter>", Accent: "#d9480f", Stylesheet: "h1 { font-size: 3em; }"}))
+
+	body := serve(h, http.MethodGet, "/", "").Body.String()
+	for _, want := range []string{"<title>ACME &lt;Greeter&gt;</title>", "background: #d9480f", "h1 { font-size: 3em; }"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("branded page lacks %q", want)
+		}
+	}
+	if strings.Contains(body, DefaultAccent) {
+		t.Error("branded page still uses the default accent")
+	}
+
+	body = serve(New(), http.MethodGet, "/", "").Body.String()
+	if !strings.Contains(body, "<h1>"+DefaultTitle+"</h1>") || !strings.Contains(body, DefaultAccent) {
+		t.Error("default page lost its title or accent")
+	}
+}
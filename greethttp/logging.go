@@ -0,0 +1,224 @@
+package greethttp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// HeaderRequestID carries the request ID. An incoming ID is reused if it
+// is well formed; either way it is echoed in the response.
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLength bounds the length of an incoming request ID.
+const maxRequestIDLength = 128
+
+// RequestLog describes a served request. Logging writes one per request as
+// a line of JSON and passes it to the Tracer.
+type RequestLog struct {
+	Time       time.Time `json:"time"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Query      string    `json:"query,omitempty"` // with names redacted
+	Status     int       `json:"status"`
+	LatencyMS  float64   `json:"latency_ms"`
+	Bytes      int64     `json:"bytes"`
+	RequestID  string    `json:"request_id"`
+	TraceID    string    `json:"trace_id"`
+	SpanID     string    `json:"span_id"`
+	ParentID   string    `json:"parent_id,omitempty"`
+	RemoteAddr string    `json:"remote_addr,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+}
+
+// Tracer is a hook for a tracing system. Start is called before a request
+// is served, with the request's TraceContext already in ctx, and may return
+// a derived context; End is called with that context once the response has
+// been written.
+type Tracer interface {
+	Start(ctx context.Context, r *http.Request) context.Context
+	End(ctx context.Context, log RequestLog)
+}
+
+// RedactName replaces a name with a fixed placeholder. It is the default
+// redactor of Logging.
+func RedactName(name string) string {
+	return "[redacted]"
+}
+
+// HashName replaces a name with a short SHA-256 digest, so that requests for
+// the same name can be correlated without logging it.
+func HashName(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// LogOption configures Logging.
+type LogOption func(*logConfig)
+
+type logConfig struct {
+	out    io.Writer
+	tracer Tracer
+	redact func(string) string
+}
+
+// WithLogOutput writes the request log to w instead of os.Stderr. A nil w
+// disables the log, e.g. when only a Tracer is wanted.
+func WithLogOutput(w io.Writer) LogOption {
+	return func(cfg *logConfig) {
+		cfg.out = w
+	}
+}
+
+// WithTracer notifies t of every request.
+func WithTracer(t Tracer) LogOption {
+	return func(cfg *logConfig) {
+		cfg.tracer = t
+	}
+}
+
+// WithNameRedactor rewrites the "name" query parameter with f before it is
+// logged. The default is RedactName; nil logs names unchanged.
+func WithNameRedactor(f func(name string) string) LogOption {
+	return func(cfg *logConfig) {
+		cfg.redact = f
+	}
+}
+
+// Logging wraps next with structured request logging and W3C Trace Context
+// propagation. For each request it:
+//
+//   - reuses a well-formed X-Request-ID header or generates one, and echoes
+//     it in the response;
+//   - continues the trace of the traceparent header in a new span, or starts
+//     a trace, and stores it in the request context (see TraceFromContext);
+//   - writes a RequestLog as one line of JSON once the response is written.
+//
+// Names in the query are redacted as configured by WithNameRedactor.
+//
+// Example:
+//
+//	h := greethttp.Logging(greethttp.New(), greethttp.WithNameRedactor(greethttp.HashName))
+//	log.Fatal(http.ListenAndServe(":8080", h))
+//
+// Thread Safety:
+//   The returned handler is safe for concurrent use; log lines are written
+//   whole, one Write call each.
+func Logging(next http.Handler, opts ...LogOption) http.Handler {
+	l := &loggingHandler{
+		next: next,
+		cfg:  logConfig{out: os.Stderr, redact: RedactName},
+	}
+	for _, opt := range opts {
+		opt(&l.cfg)
+	}
+	return l
+}
+
+type loggingHandler struct {
+	next http.Handler
+	cfg  logConfig
+	mu   sync.Mutex // serializes writes to cfg.out
+}
+
+// ServeHTTP implements http.Handler.
+func (l *loggingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	id := r.Header.Get(HeaderRequestID)
+	if !validRequestID(id) {
+		id = randomHex(16)
+	}
+	tc := traceFromRequest(r)
+
+	ctx := ContextWithTrace(context.WithValue(r.Context(), requestIDKey{}, id), tc)
+	if l.cfg.tracer != nil {
+		ctx = l.cfg.tracer.Start(ctx, r)
+	}
+	w.Header().Set(HeaderRequestID, id)
+	rec := &statusRecorder{ResponseWriter: w}
+	l.next.ServeHTTP(rec, r.WithContext(ctx))
+
+	entry := RequestLog{
+		Time:       start.UTC(),
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Query:      l.redactQuery(r.URL.RawQuery),
+		Status:     rec.code(),
+		LatencyMS:  float64(time.Since(start)) / float64(time.Millisecond),
+		Bytes:      rec.bytes,
+		RequestID:  id,
+		TraceID:    tc.TraceID,
+		SpanID:     tc.SpanID,
+		ParentID:   tc.ParentID,
+		RemoteAddr: r.RemoteAddr,
+		UserAgent:  r.UserAgent(),
+	}
+	if l.cfg.tracer != nil {
+		l.cfg.tracer.End(ctx, entry)
+	}
+	if l.cfg.out != nil {
+		l.write(&entry)
+	}
+}
+
+func (l *loggingHandler) write(entry *RequestLog) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+	l.mu.Lock()
+	l.cfg.out.Write(line)
+	l.mu.Unlock()
+}
+
+// redactQuery rewrites the name parameters of a raw query.
+func (l *loggingHandler) redactQuery(raw string) string {
+	if raw == "" || l.cfg.redact == nil {
+		return raw
+	}
+	q, err := url.ParseQuery(raw)
+	if err != nil {
+		// Do not risk logging a name we failed to find.
+		return l.cfg.redact(raw)
+	}
+	names, ok := q["name"]
+	if !ok {
+		return raw
+	}
+	for i, name := range names {
+		names[i] = l.cfg.redact(name)
+	}
+	return q.Encode()
+}
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the request ID assigned by Logging, or ""
+// if ctx did not come from a logged request.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// validRequestID reports whether an incoming request ID is safe to reuse:
+// non-empty, bounded and limited to characters that need no escaping.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == ':') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,174 @@
+package greethttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// logRequest serves one request through Logging and decodes its log line
+func logRequest(t *testing.T, target string, header http.Header, opts ...LogOption) (*httptest.ResponseRecorder, RequestLog) {
+	t.Helper()
+	var out bytes.Buffer
+	h := Logging(New(), append([]LogOption{WithLogOutput(&out)}, opts...)...)
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	for k, vs := range header {
+		for _, v := range vs {
+			r.Header.Add(k, v)
+		}
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	var entry RequestLog
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("log line %q: %v", out.String(), err)
+	}
+	if n := strings.Count(out.String(), "\n"); n != 1 {
+		t.Errorf("got %d log lines, want 1", n)
+	}
+	return w, entry
+}
+
+// TestLoggingEntry tests the fields of the JSON request log
+func TestLoggingEntry(t *testing.T) {
+	w, entry := logRequest(t, "/api/simple?name=Alice&x=1", http.Header{"User-Agent": {"test-agent"}})
+
+	if entry.Method != http.MethodGet || entry.Path != "/api/simple" || entry.Status != http.StatusOK {
+		t.Errorf("entry = %+v", entry)
+	}
+	if entry.Bytes != int64(w.Body.Len()) || entry.Bytes == 0 {
+		t.Errorf("bytes = %d, body is %d", entry.Bytes, w.Body.Len())
+	}
+	if entry.LatencyMS < 0 || entry.Time.IsZero() || entry.UserAgent != "test-agent" {
+		t.Errorf("entry = %+v", entry)
+	}
+	if entry.RequestID == "" || w.Header().Get(HeaderRequestID) != entry.RequestID {
+		t.Errorf("request ID %q, response header %q", entry.RequestID, w.Header().Get(HeaderRequestID))
+	}
+	if entry.Query != "name=%5Bredacted%5D&x=1" {
+		t.Errorf("query = %q, want name redacted", entry.Query)
+	}
+
+	_, entry = logRequest(t, "/nowhere", nil)
+	if entry.Status != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", entry.Status)
+	}
+}
+
+// TestLoggingRedaction tests the name redactors
+func TestLoggingRedaction(t *testing.T) {
+	tests := []struct {
+		redact   func(string) string
+		target   string
+		expected string
+	}{
+		{RedactName, "/greet?name=Alice", "name=%5Bredacted%5D"},
+		{HashName, "/greet?name=Alice", "name=" + strings.ReplaceAll(HashName("Alice"), ":", "%3A")},
+		{nil, "/greet?name=Alice", "name=Alice"},
+		{RedactName, "/greet?x=1", "x=1"},
+		{RedactName, "/greet?name=%zz", "[redacted]"},
+	}
+
+	for _, tt := range tests {
+		_, entry := logRequest(t, tt.target, nil, WithNameRedactor(tt.redact))
+		if entry.Query != tt.expected {
+			t.Errorf("%s: query = %q, want %q", tt.target, entry.Query, tt.expected)
+		}
+	}
+
+	if HashName("Alice") == HashName("Bob") || !strings.HasPrefix(HashName("Alice"), "sha256:") {
+		t.Errorf("HashName(Alice) = %q", HashName("Alice"))
+	}
+}
+
+// TestLoggingRequestID tests reuse and replacement of incoming request IDs
+func TestLoggingRequestID(t *testing.T) {
+	tests := []struct {
+		header string
+		reused bool
+	}{
+		{"abc-123_X.y:z", true},
+		{"", false},
+		{"has space", false},
+		{"<script>", false},
+		{strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+
+	for _, tt := range tests {
+		_, entry := logRequest(t, "/health", http.Header{HeaderRequestID: {tt.header}})
+		if (entry.RequestID == tt.header) != tt.reused || !validRequestID(entry.RequestID) {
+			t.Errorf("X-Request-ID %q logged as %q, want reused %v", tt.header, entry.RequestID, tt.reused)
+		}
+	}
+}
+
+// recordingTracer records the hook calls of Logging
+type recordingTracer struct {
+	started TraceContext
+	ended   RequestLog
+	ctxOK   bool
+}
+
+type tracerKey struct{}
+
+func (rt *recordingTracer) Start(ctx context.Context, r *http.Request) context.Context {
+	rt.started, _ = TraceFromContext(ctx)
+	return context.WithValue(ctx, tracerKey{}, "span")
+}
+
+func (rt *recordingTracer) End(ctx context.Context, log RequestLog) {
+	rt.ctxOK = ctx.Value(tracerKey{}) == "span"
+	rt.ended = log
+}
+
+// TestLoggingTrace tests traceparent propagation and the Tracer hook
+func TestLoggingTrace(t *testing.T) {
+	const parent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	rt := &recordingTracer{}
+	_, entry := logRequest(t, "/health", http.Header{
+		HeaderTraceparent: {parent},
+		HeaderTracestate:  {"vendor=abc"},
+	}, WithTracer(rt))
+
+	if entry.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || entry.ParentID != "00f067aa0ba902b7" {
+		t.Errorf("entry trace = %s/%s", entry.TraceID, entry.ParentID)
+	}
+	if !isTraceID(entry.SpanID) || len(entry.SpanID) != 16 || entry.SpanID == entry.ParentID {
+		t.Errorf("span ID = %q", entry.SpanID)
+	}
+	if rt.started.SpanID != entry.SpanID || rt.started.State != "vendor=abc" || !rt.started.Sampled() {
+		t.Errorf("tracer started with %+v", rt.started)
+	}
+	if !rt.ctxOK || rt.ended.RequestID != entry.RequestID {
+		t.Errorf("tracer ended with %+v, context kept %v", rt.ended, rt.ctxOK)
+	}
+
+	_, entry = logRequest(t, "/health", http.Header{HeaderTraceparent: {"garbage"}})
+	if len(entry.TraceID) != 32 || !isTraceID(entry.TraceID) || entry.ParentID != "" {
+		t.Errorf("new trace = %+v", entry)
+	}
+}
+
+// TestLoggingContext tests that the handler sees the request ID and trace
+func TestLoggingContext(t *testing.T) {
+	var id string
+	var tc TraceContext
+	h := Logging(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = RequestIDFromContext(r.Context())
+		tc, _ = TraceFromContext(r.Context())
+	}), WithLogOutput(nil))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if id == "" || id != w.Header().Get(HeaderRequestID) || tc.TraceID == "" {
+		t.Errorf("handler saw request ID %q and trace %+v", id, tc)
+	}
+	if RequestIDFromContext(context.Background()) != "" {
+		t.Error("RequestIDFromContext of a bare context is not empty")
+	}
+}
@@ -0,0 +1,325 @@
+package greethttp
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zhangbaodong/test"
+)
+
+// metricsContentType is the Prometheus text exposition format, version 0.0.4.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the request
+// latency histogram.
+var DefaultLatencyBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+
+// Validation failure reasons reported by greet_validation_failures_total.
+const (
+	reasonEmpty        = "empty"
+	reasonControlChars = "control_chars"
+	reasonTooLong      = "too_long"
+	reasonOther        = "other"
+)
+
+// Metrics collects request and cache metrics for Handlers and serves them
+// in the Prometheus text exposition format. It has no dependency on a
+// Prometheus client library, so tests can scrape it in-process.
+//
+// Exposed metrics:
+//
+//	greet_http_requests_total{endpoint,locale,code}   counter
+//	greet_http_request_duration_seconds{endpoint}      histogram
+//	greet_http_requests_in_flight                      gauge
+//	greet_validation_failures_total{reason}            counter
+//	greet_cache_{hits,misses,evictions}_total{cache}   counter
+//	greet_cache_hit_ratio{cache}                       gauge
+//	greet_cache_entries{cache}                         gauge
+//
+// Example:
+//
+//	m := greethttp.NewMetrics()
+//	h := greethttp.New(greethttp.WithMetrics(m)) // also serves GET /metrics
+//
+// Thread Safety:
+//   Metrics is safe for concurrent use and may be shared by several Handlers.
+type Metrics struct {
+	buckets  []float64
+	inFlight int64 // accessed atomically
+
+	mu         sync.Mutex
+	requests   map[requestKey]uint64
+	latencies  map[string]*histogram
+	validation map[string]uint64
+	caches     map[string]*test.GreetingCache
+}
+
+type requestKey struct {
+	endpoint, locale string
+	code             int
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative; the last one is +Inf
+	sum    float64
+	count  uint64
+}
+
+// NewMetrics returns an empty Metrics using DefaultLatencyBuckets.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		buckets:    DefaultLatencyBuckets,
+		requests:   make(map[requestKey]uint64),
+		latencies:  make(map[string]*histogram),
+		validation: make(map[string]uint64),
+		caches:     make(map[string]*test.GreetingCache),
+	}
+}
+
+// TrackCache exports the counters of c under the label cache=name.
+func (m *Metrics) TrackCache(name string, c *test.GreetingCache) {
+	m.mu.Lock()
+	m.caches[name] = c
+	m.mu.Unlock()
+}
+
+// begin records the start of a request and returns the function that
+// records its end.
+func (m *Metrics) begin(endpoint, locale string) func(code int) {
+	atomic.AddInt64(&m.inFlight, 1)
+	start := time.Now()
+	return func(code int) {
+		elapsed := time.Since(start).Seconds()
+		atomic.AddInt64(&m.inFlight, -1)
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.requests[requestKey{endpoint, locale, code}]++
+		h := m.latencies[endpoint]
+		if h == nil {
+			h = &histogram{counts: make([]uint64, len(m.buckets)+1)}
+			m.latencies[endpoint] = h
+		}
+		i := sort.SearchFloat64s(m.buckets, elapsed)
+		h.counts[i]++
+		h.sum += elapsed
+		h.count++
+	}
+}
+
+// validationFailed counts a rejected name by the reason it was rejected.
+func (m *Metrics) validationFailed(err error) {
+	reason := reasonOther
+	switch {
+	case errors.Is(err, test.ErrEmptyName):
+		reason = reasonEmpty
+	case errors.Is(err, test.ErrControlChars):
+		reason = reasonControlChars
+	case errors.Is(err, test.ErrTooLong):
+		reason = reasonTooLong
+	}
+	m.mu.Lock()
+	m.validation[reason]++
+	m.mu.Unlock()
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", metricsContentType)
+	m.WriteTo(w)
+}
+
+// WriteTo writes the metrics to w in the Prometheus text format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	cw := &countingWriter{w: bw}
+	m.mu.Lock()
+	m.write(cw)
+	m.mu.Unlock()
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+	return cw.n, bw.Flush()
+}
+
+func (m *Metrics) write(w *countingWriter) {
+	header(w, "greet_http_requests_total", "counter", "HTTP requests by endpoint, locale and status code.")
+	keys := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.endpoint != b.endpoint {
+			return a.endpoint < b.endpoint
+		}
+		if a.locale != b.locale {
+			return a.locale < b.locale
+		}
+		return a.code < b.code
+	})
+	for _, k := range keys {
+		sample(w, "greet_http_requests_total", labels("endpoint", k.endpoint, "locale", k.locale, "code", strconv.Itoa(k.code)), float64(m.requests[k]))
+	}
+
+	header(w, "greet_http_request_duration_seconds", "histogram", "HTTP request latency by endpoint.")
+	endpoints := make([]string, 0, len(m.latencies))
+	for endpoint := range m.latencies {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+	for _, endpoint := range endpoints {
+		h := m.latencies[endpoint]
+		var cumulative uint64
+		for i, le := range m.buckets {
+			cumulative += h.counts[i]
+			sample(w, "greet_http_request_duration_seconds_bucket", labels("endpoint", endpoint, "le", formatFloat(le)), float64(cumulative))
+		}
+		sample(w, "greet_http_request_duration_seconds_bucket", labels("endpoint", endpoint, "le", "+Inf"), float64(h.count))
+		sample(w, "greet_http_request_duration_seconds_sum", labels("endpoint", endpoint), h.sum)
+		sample(w, "greet_http_request_duration_seconds_count", labels("endpoint", endpoint), float64(h.count))
+	}
+
+	header(w, "greet_http_requests_in_flight", "gauge", "HTTP requests currently being served.")
+	sample(w, "greet_http_requests_in_flight", "", float64(atomic.LoadInt64(&m.inFlight)))
+
+	header(w, "greet_validation_failures_total", "counter", "Names rejected by validation, by reason.")
+	reasons := make([]string, 0, len(m.validation))
+	for reason := range m.validation {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		sample(w, "greet_validation_failures_total", labels("reason", reason), float64(m.validation[reason]))
+	}
+
+	if len(m.caches) == 0 {
+		return
+	}
+	names := make([]string, 0, len(m.caches))
+	stats := make(map[string]test.CacheStats, len(m.caches))
+	for name, c := range m.caches {
+		names = append(names, name)
+		stats[name] = c.Stats()
+	}
+	sort.Strings(names)
+	cacheMetrics := []struct {
+		name, typ, help string
+		value           func(s test.CacheStats) float64
+	}{
+		{"greet_cache_hits_total", "counter", "Greeting cache hits.", func(s test.CacheStats) float64 { return float64(s.Hits) }},
+		{"greet_cache_misses_total", "counter", "Greeting cache misses.", func(s test.CacheStats) float64 { return float64(s.Misses) }},
+		{"greet_cache_evictions_total", "counter", "Greeting cache entries evicted for space or expired.", func(s test.CacheStats) float64 { return float64(s.Evictions + s.Expirations) }},
+		{"greet_cache_hit_ratio", "gauge", "Fraction of greeting cache lookups that hit.", func(s test.CacheStats) float64 {
+			if s.Hits+s.Misses == 0 {
+				return 0
+			}
+			return float64(s.Hits) / float64(s.Hits+s.Misses)
+		}},
+		{"greet_cache_entries", "gauge", "Greetings currently cached.", func(s test.CacheStats) float64 { return float64(s.Entries) }},
+	}
+	for _, cm := range cacheMetrics {
+		header(w, cm.name, cm.typ, cm.help)
+		for _, name := range names {
+			sample(w, cm.name, labels("cache", name), cm.value(stats[name]))
+		}
+	}
+}
+
+// countingWriter remembers the first write error and the bytes written.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) WriteString(s string) {
+	if c.err != nil {
+		return
+	}
+	n, err := io.WriteString(c.w, s)
+	c.n += int64(n)
+	c.err = err
+}
+
+func header(w *countingWriter, name, typ, help string) {
+	w.WriteString("# HELP " + name + " " + help + "\n")
+	w.WriteString("# TYPE " + name + " " + typ + "\n")
+}
+
+func sample(w *countingWriter, name, labels string, value float64) {
+	w.WriteString(name + labels + " " + formatFloat(value) + "\n")
+}
+
+// labels formats name/value pairs as a label set, escaping the values.
+func labels(pairs ...string) string {
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i := 0; i < len(pairs); i += 2 {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(pairs[i])
+		sb.WriteString(`="`)
+		sb.WriteString(labelEscaper.Replace(pairs[i+1]))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// statusRecorder captures the status code and body size written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher if the underlying writer does.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *statusRecorder) code() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
@@ -0,0 +1,160 @@
+package greethttp
+
+import (
+	"bufio"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zhangbaodong/test"
+)
+
+// scrape fetches /metrics from h and parses the samples by series
+func scrape(t *testing.T, h http.Handler) map[string]float64 {
+	t.Helper()
+	w := serve(h, http.MethodGet, "/metrics", "")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != metricsContentType {
+		t.Fatalf("GET /metrics = %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	samples := make(map[string]float64)
+	sc := bufio.NewScanner(w.Body)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		v, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("bad sample %q: %v", line, err)
+		}
+		samples[line[:i]] = v
+	}
+	return samples
+}
+
+// TestMetricsRequests tests request counters, latency histograms and validation failures
+func TestMetricsRequests(t *testing.T) {
+	m := NewMetrics()
+	h := New(WithMetrics(m))
+
+	for _, target := range []string{
+		"/api/greet?name=Alice",
+		"/api/greet?name=Bob",
+		"/api/simple?name=%07",
+		"/greet?name=" + strings.Repeat("x", test.MaxNameLength+1),
+		"/nowhere",
+	} {
+		serve(h, http.MethodGet, target, "")
+	}
+	serve(h, http.MethodPost, "/api/greet", "")
+
+	samples := scrape(t, h)
+	expected := map[string]float64{
+		`greet_http_requests_total{endpoint="/api/greet",locale="en",code="200"}`:     2,
+		`greet_http_requests_total{endpoint="/api/greet",locale="en",code="405"}`:     1,
+		`greet_http_requests_total{endpoint="/api/simple",locale="en",code="400"}`:    1,
+		`greet_http_requests_total{endpoint="/greet",locale="en",code="400"}`:         1,
+		`greet_http_requests_total{endpoint="other",locale="en",code="404"}`:          1,
+		`greet_http_request_duration_seconds_bucket{endpoint="/api/greet",le="+Inf"}`: 3,
+		`greet_http_request_duration_seconds_count{endpoint="/api/greet"}`:            3,
+		`greet_http_requests_in_flight`:                                               1, // the scrape itself
+		`greet_validation_failures_total{reason="control_chars"}`:                     1,
+		`greet_validation_failures_total{reason="too_long"}`:                          1,
+	}
+	for series, want := range expected {
+		if got, ok := samples[series]; !ok || got != want {
+			t.Errorf("%s = %v (present %v), want %v", series, got, ok, want)
+		}
+	}
+
+	// Buckets are cumulative.
+	prev := 0.0
+	for _, le := range DefaultLatencyBuckets {
+		series := `greet_http_request_duration_seconds_bucket{endpoint="/api/greet",le="` + formatFloat(le) + `"}`
+		got, ok := samples[series]
+		if !ok || got < prev {
+			t.Errorf("%s = %v after %v", series, got, prev)
+		}
+		prev = got
+	}
+
+	// The first scrape is counted by the second.
+	if got := scrape(t, h)[`greet_http_requests_total{endpoint="/metrics",locale="en",code="200"}`]; got != 1 {
+		t.Errorf("/metrics requests = %v, want 1", got)
+	}
+}
+
+// TestMetricsCache tests the exported greeting cache counters
+func TestMetricsCache(t *testing.T) {
+	cache := test.NewGreetingCache(test.CacheConfig{MaxEntries: 100})
+	g, err := test.NewGreeter("de", test.WithCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMetrics()
+	m.TrackCache("greetings", cache)
+	h := New(WithGreeter(g), WithMetrics(m))
+
+	for _, name := range []string{"Anna", "Anna", "Anna", "Ben"} {
+		serve(h, http.MethodGet, "/api/simple?name="+name, "")
+	}
+
+	samples := scrape(t, h)
+	expected := map[string]float64{
+		`greet_http_requests_total{endpoint="/api/simple",locale="de",code="200"}`: 4,
+		`greet_cache_hits_total{cache="greetings"}`:                                2,
+		`greet_cache_misses_total{cache="greetings"}`:                              2,
+		`greet_cache_hit_ratio{cache="greetings"}`:                                 0.5,
+		`greet_cache_entries{cache="greetings"}`:                                   2,
+		`greet_cache_evictions_total{cache="greetings"}`:                           0,
+	}
+	for series, want := range expected {
+		if got, ok := samples[series]; !ok || got != want {
+			t.Errorf("%s = %v (present %v), want %v", series, got, ok, want)
+		}
+	}
+}
+
+// TestMetricsFormat tests label escaping and that metrics are off by default
+func TestMetricsFormat(t *testing.T) {
+	if got := labels("a", `x"y\z`+"\n", "b", ""); got != `{a="x\"y\\z\n",b=""}` {
+		t.Errorf("labels = %s", got)
+	}
+
+	if w := serve(New(), http.MethodGet, "/metrics", ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET /metrics without WithMetrics = %d, want 404", w.Code)
+	}
+
+	var sb strings.Builder
+	if _, err := NewMetrics().WriteTo(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "# TYPE greet_http_request_duration_seconds histogram\n") {
+		t.Errorf("empty metrics missing TYPE lines:\n%s", sb.String())
+	}
+}
+
+// TestMetricsConcurrent tests counting under concurrent requests
+func TestMetricsConcurrent(t *testing.T) {
+	m := NewMetrics()
+	h := New(WithMetrics(m))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				serve(h, http.MethodGet, "/health", "")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := scrape(t, h)[`greet_http_requests_total{endpoint="/health",locale="en",code="200"}`]; got != 400 {
+		t.Errorf("/health requests = %v, want 400", got)
+	}
+}
@@ -0,0 +1,81 @@
+package greethttp
+
+import (
+	"strconv"
+	"strings"
+)
+
+// acceptRange is one entry of an Accept-style header.
+type acceptRange struct {
+	value string
+	q     float64
+}
+
+// parseAccept parses an Accept-style header such as
+// "text/html, application/json;q=0.9, */*;q=0.1". Entries with a malformed
+// q parameter are treated as q=0, i.e. not acceptable.
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		value := strings.ToLower(strings.TrimSpace(fields[0]))
+		if value == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			name, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.ToLower(strings.TrimSpace(name)) != "q" {
+				continue
+			}
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil || f < 0 || f > 1 {
+				f = 0
+			}
+			q = f
+		}
+		ranges = append(ranges, acceptRange{value: value, q: q})
+	}
+	return ranges
+}
+
+// negotiate picks the offer the Accept header prefers. Offers are media
+// types in the server's order of preference, which breaks ties. An empty
+// header accepts the first offer; "" is returned if nothing is acceptable.
+func negotiate(accept string, offers []string) string {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+	ranges := parseAccept(accept)
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := mediaQuality(ranges, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// mediaQuality returns the q-value of the most specific range matching the
+// media type offer.
+func mediaQuality(ranges []acceptRange, offer string) float64 {
+	typ, _, _ := strings.Cut(offer, "/")
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		s := -1
+		switch {
+		case r.value == offer:
+			s = 2
+		case r.value == typ+"/*":
+			s = 1
+		case r.value == "*/*":
+			s = 0
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
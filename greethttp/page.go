@@ -0,0 +1,100 @@
+package greethttp
+
+import "html/template"
+
+// DefaultTitle is the title of the greeting page without WithBranding.
+const DefaultTitle = "Greeting Service"
+
+// DefaultAccent is the color of buttons and highlights without
+// WithBranding.
+const DefaultAccent = "#007bff"
+
+// Branding customizes the look of the HTML greeting page, e.g. per tenant.
+// Empty fields keep the defaults.
+type Branding struct {
+	Title  string // page title and heading
+	Accent string // CSS color of buttons, links and the greeting's border, e.g. "#d9480f"
+	// Stylesheet is CSS added after the built-in rules. It is trusted and
+	// included as is, so it must not come from users.
+	Stylesheet string
+}
+
+// withDefaults returns b with its empty fields set to the defaults.
+func (b Branding) withDefaults() Branding {
+	if b.Title == "" {
+		b.Title = DefaultTitle
+	}
+	if b.Accent == "" {
+		b.Accent = DefaultAccent
+	}
+	return b
+}
+
+// pageTemplate renders the greeting form. Links are relative so the page
+// keeps working when the Handler is mounted under a path prefix.
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <title>{{.Brand.Title}}</title>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <style>
+        body { font-family: Arial, sans-serif; margin: 40px; background: #f5f5f5; }
+        .container { max-width: 600px; margin: 0 auto; background: white; padding: 30px; border-radius: 8px; box-shadow: 0 2px 10px rgba(0,0,0,0.1); }
+        .form-group { margin: 20px 0; }
+        input[type="text"] { padding: 12px; width: 250px; border: 1px solid #ddd; border-radius: 4px; font-size: 16px; }
+        button { padding: 12px 24px; background: {{.Brand.Accent}}; color: white; border: none; border-radius: 4px; cursor: pointer; font-size: 16px; }
+        button:hover { filter: brightness(85%); }
+        .greeting { margin: 20px 0; padding: 20px; background: #f8f9fa; border-radius: 5px; border-left: 4px solid {{.Brand.Accent}}; }
+        .error { margin: 20px 0; padding: 20px; background: #fdecea; border-radius: 5px; border-left: 4px solid #d93025; }
+        .api-links { margin-top: 30px; }
+        .api-links a { color: {{.Brand.Accent}}; text-decoration: none; }
+        .api-links a:hover { text-decoration: underline; }
+        {{.Stylesheet}}
+    </style>
+</head>
+<body>
+    <div class="container">
+        <h1>{{.Brand.Title}}</h1>
+
+        <form method="GET" action="greet">
+            <div class="form-group">
+                <label for="name">Enter your name:</label><br>
+                <input type="text" id="name" name="name" value="{{.Name}}" placeholder="Your name" autocomplete="name">
+            </div>
+            <button type="submit">Get Greeting</button>
+        </form>
+
+        {{if .Greeting}}
+        <div class="greeting">
+            <h3>{{.Greeting}}</h3>
+        </div>
+        {{end}}
+        {{if .Error}}
+        <div class="error">
+            <h3>{{.Error}}</h3>
+        </div>
+        {{end}}
+
+        <div class="api-links">
+            <h3>API Endpoints:</h3>
+            <ul>
+                <li><a href="api/greet?name=World">api/greet?name=World</a></li>
+                <li><a href="api/greet?name=Alice">api/greet?name=Alice</a></li>
+                <li><a href="api/greet">api/greet (defaults to {{.DefaultName}})</a></li>
+            </ul>
+        </div>
+    </div>
+</body>
+</html>
+`))
+
+// pageData is the data rendered by pageTemplate.
+type pageData struct {
+	Name        string
+	Greeting    string
+	Error       string
+	DefaultName string
+	Brand       Branding
+	Stylesheet  template.CSS // Brand.Stylesheet, trusted
+}
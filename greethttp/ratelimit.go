@@ -0,0 +1,297 @@
+package greethttp
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zhangbaodong/test"
+)
+
+// Rate limit headers, following the IETF RateLimit header fields draft.
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRateLimitPolicy    = "RateLimit-Policy"
+)
+
+// rateLimitSweepInterval is how often MemoryRateLimitStore drops buckets
+// that have refilled completely and so carry no state.
+const rateLimitSweepInterval = time.Minute
+
+// RateLimitPolicy configures a token bucket: a client may make Burst
+// requests at once, and regains Rate requests per second up to Burst.
+type RateLimitPolicy struct {
+	Rate  float64
+	Burst int
+}
+
+// window is how long an empty bucket takes to refill.
+func (p RateLimitPolicy) window() time.Duration {
+	return time.Duration(float64(p.Burst) / p.Rate * float64(time.Second))
+}
+
+// RateLimitResult is the outcome of taking a token from a bucket.
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int           // whole tokens left after this request
+	RetryAfter time.Duration // until a token is available, if not allowed
+	Reset      time.Duration // until the bucket is full again
+}
+
+// RateLimitStore keeps the token buckets of RateLimiting. Implementations
+// backed by a shared store such as Redis let several servers enforce one
+// limit.
+type RateLimitStore interface {
+	// Take takes a token from the bucket of key under policy p. A key not
+	// seen before starts with a full bucket.
+	Take(ctx context.Context, key string, p RateLimitPolicy) (RateLimitResult, error)
+}
+
+// MemoryRateLimitStore is a RateLimitStore kept in process memory. Buckets
+// that have refilled are dropped periodically, so memory is bounded by the
+// number of clients active within one refill window.
+//
+// Thread Safety:
+//   A MemoryRateLimitStore is safe for concurrent use by multiple goroutines.
+type MemoryRateLimitStore struct {
+	clock test.Clock
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time // when the bucket will be full, for sweeping
+}
+
+// NewMemoryRateLimitStore returns an empty store reading time from clock,
+// or from test.SystemClock if clock is nil.
+func NewMemoryRateLimitStore(clock test.Clock) *MemoryRateLimitStore {
+	if clock == nil {
+		clock = test.SystemClock
+	}
+	return &MemoryRateLimitStore{
+		clock:     clock,
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: clock.Now(),
+	}
+}
+
+// Take implements RateLimitStore.
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, p RateLimitPolicy) (RateLimitResult, error) {
+	now := s.clock.Now()
+	burst := float64(p.Burst)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastSweep) >= rateLimitSweepInterval {
+		s.sweep(now)
+	}
+
+	b := s.buckets[key]
+	if b == nil {
+		b = &tokenBucket{tokens: burst, last: now}
+		s.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed.Seconds()*p.Rate)
+		b.last = now
+	}
+
+	var res RateLimitResult
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / p.Rate)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((burst - b.tokens) / p.Rate)
+	b.full = now.Add(res.Reset)
+	return res, nil
+}
+
+// Len returns the number of buckets held.
+func (s *MemoryRateLimitStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buckets)
+}
+
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+func seconds(f float64) time.Duration {
+	return time.Duration(f * float64(time.Second))
+}
+
+// KeyFunc identifies the client a request counts against.
+type KeyFunc func(r *http.Request) string
+
+// ClientIP keys requests by the IP address of the connection. Behind a
+// reverse proxy that is the proxy's address; use a KeyFunc that reads the
+// header the proxy sets instead.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// APIKeyOrIP keys requests by the API key in header if valid accepts it,
+// and by ClientIP otherwise. Only keys valid accepts get buckets of their
+// own, so a client cannot escape its IP's limit by sending a made-up key
+// with every request. A nil valid accepts no key.
+//
+// Example:
+//
+//	key := greethttp.APIKeyOrIP("X-API-Key", greethttp.APIKeySet("k1", "k2"))
+func APIKeyOrIP(header string, valid func(key string) bool) KeyFunc {
+	return func(r *http.Request) string {
+		if key := r.Header.Get(header); key != "" && valid != nil && valid(key) {
+			return "key:" + key
+		}
+		return ClientIP(r)
+	}
+}
+
+// APIKeySet returns a validator for APIKeyOrIP accepting exactly keys.
+func APIKeySet(keys ...string) func(key string) bool {
+	set := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		set[k] = true
+	}
+	return func(key string) bool {
+		return set[key]
+	}
+}
+
+// RateLimitOption configures RateLimiting.
+type RateLimitOption func(*RateLimiter)
+
+// WithRateLimitStore keeps buckets in s instead of a MemoryRateLimitStore.
+func WithRateLimitStore(s RateLimitStore) RateLimitOption {
+	return func(l *RateLimiter) {
+		l.store = s
+	}
+}
+
+// WithRateLimitKey identifies clients with f instead of ClientIP.
+func WithRateLimitKey(f KeyFunc) RateLimitOption {
+	return func(l *RateLimiter) {
+		l.key = f
+	}
+}
+
+// RateLimiter is the handler returned by RateLimiting.
+//
+// Thread Safety:
+//   A RateLimiter is safe for concurrent use if its store is; SetPolicy may
+//   be called while it serves requests.
+type RateLimiter struct {
+	next   http.Handler
+	store  RateLimitStore
+	key    KeyFunc
+	policy atomic.Value // activePolicy
+}
+
+// activePolicy is a policy with its precomputed RateLimit-Policy header.
+type activePolicy struct {
+	RateLimitPolicy
+	header string
+}
+
+// RateLimiting wraps next with per-client token bucket rate limiting.
+// Every response carries RateLimit-Limit, RateLimit-Remaining,
+// RateLimit-Reset and RateLimit-Policy headers; throttled requests get a
+// 429 ErrorResponse with code "rate_limited" and a Retry-After header.
+// If the store fails, requests are let through.
+//
+// RateLimiting panics if p is invalid: p.Rate must be positive and p.Burst
+// at least 1.
+//
+// Example:
+//
+//	// 5 requests at once, then one every 2 seconds, per known API key or IP
+//	h := greethttp.RateLimiting(greethttp.New(),
+//		greethttp.RateLimitPolicy{Rate: 0.5, Burst: 5},
+//		greethttp.WithRateLimitKey(greethttp.APIKeyOrIP("X-API-Key", greethttp.APIKeySet(keys...))))
+func RateLimiting(next http.Handler, p RateLimitPolicy, opts ...RateLimitOption) *RateLimiter {
+	l := &RateLimiter{next: next, key: ClientIP}
+	if err := l.SetPolicy(p); err != nil {
+		panic("greethttp: " + err.Error())
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.store == nil {
+		l.store = NewMemoryRateLimitStore(nil)
+	}
+	return l
+}
+
+// SetPolicy replaces the policy, e.g. on a configuration reload. Buckets
+// keep their tokens and refill at the new rate up to the new burst.
+func (l *RateLimiter) SetPolicy(p RateLimitPolicy) error {
+	if !(p.Rate > 0) || p.Burst < 1 {
+		return fmt.Errorf("invalid rate limit policy: rate %v, burst %d", p.Rate, p.Burst)
+	}
+	header := strconv.Itoa(p.Burst) + ";w=" + strconv.FormatInt(ceilSeconds(p.window()), 10)
+	l.policy.Store(activePolicy{p, header})
+	return nil
+}
+
+// Policy returns the current policy.
+func (l *RateLimiter) Policy() RateLimitPolicy {
+	return l.policy.Load().(activePolicy).RateLimitPolicy
+}
+
+// ServeHTTP implements http.Handler.
+func (l *RateLimiter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p := l.policy.Load().(activePolicy)
+	res, err := l.store.Take(r.Context(), l.key(r), p.RateLimitPolicy)
+	if err != nil {
+		l.next.ServeHTTP(w, r)
+		return
+	}
+
+	h := w.Header()
+	h.Set(HeaderRateLimitLimit, strconv.Itoa(p.Burst))
+	h.Set(HeaderRateLimitRemaining, strconv.Itoa(res.Remaining))
+	h.Set(HeaderRateLimitReset, strconv.FormatInt(ceilSeconds(res.Reset), 10))
+	h.Set(HeaderRateLimitPolicy, p.header)
+	if !res.Allowed {
+		retry := ceilSeconds(res.RetryAfter)
+		if retry < 1 {
+			retry = 1
+		}
+		h.Set("Retry-After", strconv.FormatInt(retry, 10))
+		writeError(w, r, http.StatusTooManyRequests, CodeRateLimited,
+			"rate limit exceeded, retry in "+strconv.FormatInt(retry, 10)+"s")
+		return
+	}
+	l.next.ServeHTTP(w, r)
+}
+
+// ceilSeconds rounds d up to whole seconds, as the headers require.
+func ceilSeconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
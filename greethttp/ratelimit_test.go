@@ -0,0 +1,187 @@
+package greethttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// manualClock is a test.Clock moved by hand
+type manualClock struct{ now time.Time }
+
+func (c *manualClock) Now() time.Time          { return c.now }
+func (c *manualClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// limitedRequest serves a request from addr with an optional API key
+func limitedRequest(h http.Handler, addr, apiKey string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/api/simple?name=Alice", nil)
+	r.RemoteAddr = addr
+	if apiKey != "" {
+		r.Header.Set("X-API-Key", apiKey)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// TestRateLimiting tests bursts, refill and the response headers
+func TestRateLimiting(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1700000000, 0)}
+	h := RateLimiting(New(), RateLimitPolicy{Rate: 0.5, Burst: 3},
+		WithRateLimitStore(NewMemoryRateLimitStore(clock)))
+
+	steps := []struct {
+		advance   time.Duration
+		status    int
+		remaining string
+		reset     string
+		retry     string
+	}{
+		{0, http.StatusOK, "2", "2", ""},
+		{0, http.StatusOK, "1", "4", ""},
+		{0, http.StatusOK, "0", "6", ""},
+		{0, http.StatusTooManyRequests, "0", "6", "2"},
+		{time.Second, http.StatusTooManyRequests, "0", "5", "1"},
+		{time.Second, http.StatusOK, "0", "6", ""},
+		{10 * time.Second, http.StatusOK, "2", "2", ""},
+	}
+
+	for i, s := range steps {
+		clock.Advance(s.advance)
+		w := limitedRequest(h, "192.0.2.1:1234", "")
+		hdr := w.Header()
+		if w.Code != s.status || hdr.Get(HeaderRateLimitRemaining) != s.remaining ||
+			hdr.Get(HeaderRateLimitReset) != s.reset || hdr.Get("Retry-After") != s.retry {
+			t.Errorf("step %d: %d remaining=%s reset=%s retry=%q, want %d %s %s %q", i, w.Code,
+				hdr.Get(HeaderRateLimitRemaining), hdr.Get(HeaderRateLimitReset), hdr.Get("Retry-After"),
+				s.status, s.remaining, s.reset, s.retry)
+		}
+		if hdr.Get(HeaderRateLimitLimit) != "3" || hdr.Get(HeaderRateLimitPolicy) != "3;w=6" {
+			t.Errorf("step %d: limit %q policy %q", i, hdr.Get(HeaderRateLimitLimit), hdr.Get(HeaderRateLimitPolicy))
+		}
+		if s.status == http.StatusTooManyRequests && !strings.Contains(w.Body.String(), `"code":"`+CodeRateLimited+`"`) {
+			t.Errorf("step %d: body %s lacks %s", i, w.Body.String(), CodeRateLimited)
+		}
+	}
+}
+
+// TestRateLimitingKeys tests that clients are limited independently
+func TestRateLimitingKeys(t *testing.T) {
+	h := RateLimiting(New(), RateLimitPolicy{Rate: 1, Burst: 1},
+		WithRateLimitKey(APIKeyOrIP("X-API-Key", APIKeySet("k1", "k2"))))
+
+	tests := []struct {
+		addr, apiKey string
+		status       int
+	}{
+		{"192.0.2.1:1000", "", http.StatusOK},
+		{"192.0.2.1:2000", "", http.StatusTooManyRequests}, // same IP, other port
+		{"192.0.2.2:1000", "", http.StatusOK},
+		{"192.0.2.1:1000", "k1", http.StatusOK},
+		{"192.0.2.3:1000", "k1", http.StatusTooManyRequests}, // same key, other IP
+		{"192.0.2.3:1000", "k2", http.StatusOK},
+		{"192.0.2.2:1000", "unknown", http.StatusTooManyRequests}, // counts against its IP
+	}
+	for _, tt := range tests {
+		if w := limitedRequest(h, tt.addr, tt.apiKey); w.Code != tt.status {
+			t.Errorf("%s key %q = %d, want %d", tt.addr, tt.apiKey, w.Code, tt.status)
+		}
+	}
+}
+
+// TestRateLimitingUnknownKeys tests that made-up API keys cannot escape the IP's limit
+func TestRateLimitingUnknownKeys(t *testing.T) {
+	for _, valid := range []func(string) bool{APIKeySet("k1"), nil} {
+		h := RateLimiting(New(), RateLimitPolicy{Rate: 0.01, Burst: 1},
+			WithRateLimitKey(APIKeyOrIP("X-API-Key", valid)))
+
+		limited := 0
+		for i := 0; i < 100; i++ {
+			if w := limitedRequest(h, "192.0.2.1:1000", fmt.Sprint("junk-", i)); w.Code == http.StatusTooManyRequests {
+				limited++
+			}
+		}
+		if limited != 99 {
+			t.Errorf("%d of 100 requests with rotating unknown keys limited, want 99", limited)
+		}
+	}
+}
+
+// failingStore is a RateLimitStore that always fails
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, RateLimitPolicy) (RateLimitResult, error) {
+	return RateLimitResult{}, errors.New("store down")
+}
+
+// TestRateLimitingStoreFailure tests that requests pass when the store fails
+func TestRateLimitingStoreFailure(t *testing.T) {
+	h := RateLimiting(New(), RateLimitPolicy{Rate: 1, Burst: 1}, WithRateLimitStore(failingStore{}))
+	for i := 0; i < 3; i++ {
+		if w := limitedRequest(h, "192.0.2.1:1", ""); w.Code != http.StatusOK {
+			t.Fatalf("request %d = %d, want 200", i, w.Code)
+		}
+	}
+}
+
+// TestMemoryRateLimitStoreSweep tests that refilled buckets are dropped
+func TestMemoryRateLimitStoreSweep(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1700000000, 0)}
+	s := NewMemoryRateLimitStore(clock)
+	p := RateLimitPolicy{Rate: 1, Burst: 10}
+	ctx := context.Background()
+
+	s.Take(ctx, "a", p)
+	clock.Advance(5 * time.Second)
+	s.Take(ctx, "b", p)
+	clock.Advance(rateLimitSweepInterval - 5*time.Second)
+	s.Take(ctx, "c", p)
+	if n := s.Len(); n != 1 {
+		t.Errorf("after sweep Len = %d, want 1", n)
+	}
+}
+
+// TestRateLimitingPolicy tests that invalid policies are rejected
+func TestRateLimitingPolicy(t *testing.T) {
+	for _, p := range []RateLimitPolicy{{Rate: 0, Burst: 1}, {Rate: 1, Burst: 0}, {Rate: -1, Burst: 5}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RateLimiting(%+v) did not panic", p)
+				}
+			}()
+			RateLimiting(New(), p)
+		}()
+	}
+}
+
+// TestRateLimiterSetPolicy tests replacing the policy of a running limiter
+func TestRateLimiterSetPolicy(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1700000000, 0)}
+	l := RateLimiting(New(), RateLimitPolicy{Rate: 1, Burst: 1},
+		WithRateLimitStore(NewMemoryRateLimitStore(clock)))
+
+	if w := limitedRequest(l, "192.0.2.1:1", ""); w.Code != http.StatusOK {
+		t.Fatalf("first request = %d", w.Code)
+	}
+	if err := l.SetPolicy(RateLimitPolicy{Rate: 0, Burst: 1}); err == nil {
+		t.Error("SetPolicy accepted a zero rate")
+	}
+	if err := l.SetPolicy(RateLimitPolicy{Rate: 10, Burst: 5}); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Second)
+	w := limitedRequest(l, "192.0.2.1:1", "")
+	if w.Code != http.StatusOK || w.Header().Get(HeaderRateLimitLimit) != "5" || w.Header().Get(HeaderRateLimitPolicy) != "5;w=1" {
+		t.Errorf("after SetPolicy: %d limit %q policy %q", w.Code,
+			w.Header().Get(HeaderRateLimitLimit), w.Header().Get(HeaderRateLimitPolicy))
+	}
+	if p := l.Policy(); p.Burst != 5 {
+		t.Errorf("Policy = %+v", p)
+	}
+}
@@ -0,0 +1,232 @@
+package greethttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// DefaultDrainTimeout bounds how long Server waits for in-flight requests.
+const DefaultDrainTimeout = 30 * time.Second
+
+// Lifecycle states reported by the readiness endpoint.
+const (
+	stateStarting int32 = iota
+	stateReady
+	stateDraining
+	stateStopped
+)
+
+var stateNames = [...]string{"starting", "ready", "draining", "stopped"}
+
+// ShutdownHook runs after the server has drained, e.g. to flush caches or
+// push final metrics. ctx carries the drain timeout.
+type ShutdownHook func(ctx context.Context) error
+
+// ServerOption configures a Server.
+type ServerOption func(*Server)
+
+// WithDrainTimeout bounds how long shutdown waits for in-flight requests
+// before closing their connections, and then again how long the shutdown
+// hooks may take. The default is DefaultDrainTimeout.
+func WithDrainTimeout(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.drainTimeout = d
+	}
+}
+
+// WithDrainDelay keeps serving for d after readiness flips to draining, so
+// that load balancers polling the readiness endpoint stop sending traffic
+// before the listener closes. The default is no delay.
+func WithDrainDelay(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.drainDelay = d
+	}
+}
+
+// WithSignals sets the signals that start a graceful shutdown. The default
+// is SIGINT and SIGTERM; none disables signal handling, leaving shutdown to
+// the context passed to Run.
+func WithSignals(sig ...os.Signal) ServerOption {
+	return func(s *Server) {
+		s.signals = sig
+	}
+}
+
+// OnShutdown adds a hook run after the server has drained. Hooks run in
+// the order they were added; all run even if one fails.
+func OnShutdown(hook ShutdownHook) ServerOption {
+	return func(s *Server) {
+		s.hooks = append(s.hooks, hook)
+	}
+}
+
+// Server runs an http.Server until its context is canceled or a signal
+// arrives, then shuts it down gracefully:
+//
+//  1. the readiness endpoint starts failing with 503;
+//  2. after the drain delay, the listener closes and in-flight requests
+//     are given the drain timeout to finish;
+//  3. the shutdown hooks run.
+//
+// A second signal during the drain kills the process as usual.
+//
+// Example:
+//
+//	srv := greethttp.NewServer(&http.Server{Addr: ":8080", Handler: mux},
+//		greethttp.WithDrainDelay(5*time.Second),
+//		greethttp.OnShutdown(func(ctx context.Context) error { cache.Purge(); return nil }))
+//	mux.Handle("/readyz", srv.Readiness())
+//	mux.Handle("/livez", srv.Liveness())
+//	if err := srv.Run(context.Background()); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// Thread Safety:
+//   Run may be called once. Ready and the endpoints are safe for concurrent
+//   use.
+type Server struct {
+	srv          *http.Server
+	drainTimeout time.Duration
+	drainDelay   time.Duration
+	signals      []os.Signal
+	hooks        []ShutdownHook
+
+	state int32 // accessed atomically
+
+	drainOnce sync.Once
+	drained   chan struct{} // closed when draining starts
+}
+
+// NewServer returns a Server running srv.
+func NewServer(srv *http.Server, opts ...ServerOption) *Server {
+	s := &Server{
+		srv:          srv,
+		drainTimeout: DefaultDrainTimeout,
+		signals:      []os.Signal{os.Interrupt, syscall.SIGTERM},
+		drained:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Run listens on the server's Addr and serves until shutdown.
+func (s *Server) Run(ctx context.Context) error {
+	addr := s.srv.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, lis)
+}
+
+// Serve serves on lis until ctx is canceled or a shutdown signal arrives,
+// then shuts down gracefully. It returns nil after a clean shutdown, the
+// server's error if it failed, or an error if draining timed out or a hook
+// failed.
+func (s *Server) Serve(ctx context.Context, lis net.Listener) error {
+	stop := func() {}
+	if len(s.signals) > 0 {
+		ctx, stop = signal.NotifyContext(ctx, s.signals...)
+	}
+
+	served := make(chan error, 1)
+	atomic.StoreInt32(&s.state, stateReady)
+	go func() { served <- s.srv.Serve(lis) }()
+
+	select {
+	case err := <-served:
+		stop()
+		atomic.StoreInt32(&s.state, stateStopped)
+		return err
+	case <-ctx.Done():
+	}
+	// Restore the default signal behavior before draining, so that a
+	// second signal kills the process instead of being swallowed.
+	stop()
+	return s.shutdown(served)
+}
+
+func (s *Server) shutdown(served <-chan error) error {
+	atomic.StoreInt32(&s.state, stateDraining)
+	s.drainOnce.Do(func() { close(s.drained) })
+	if s.drainDelay > 0 {
+		time.Sleep(s.drainDelay)
+	}
+
+	var errs []error
+	ctx, cancel := context.WithTimeout(context.Background(), s.drainTimeout)
+	if err := s.srv.Shutdown(ctx); err != nil {
+		s.srv.Close()
+		errs = append(errs, fmt.Errorf("drain: %w", err))
+	}
+	cancel()
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		errs = append(errs, err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), s.drainTimeout)
+	defer cancel()
+	for i, hook := range s.hooks {
+		if err := hook(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown hook %d: %w", i, err))
+		}
+	}
+	atomic.StoreInt32(&s.state, stateStopped)
+
+	if len(errs) == 0 {
+		return nil
+	}
+	// Report the first failure; later ones are usually its consequences.
+	return errs[0]
+}
+
+// Ready reports whether the server is accepting new work: it has started
+// serving and is not draining.
+func (s *Server) Ready() bool {
+	return atomic.LoadInt32(&s.state) == stateReady
+}
+
+// Draining returns a channel closed when shutdown starts, for long-lived
+// handlers such as streams that should wind down early.
+func (s *Server) Draining() <-chan struct{} {
+	return s.drained
+}
+
+// Readiness returns a handler answering 200 {"status":"ready"} while the
+// server accepts work and 503 with the state ("starting", "draining" or
+// "stopped") otherwise. Point the load balancer's health check at it.
+func (s *Server) Readiness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := atomic.LoadInt32(&s.state)
+		status := http.StatusOK
+		if state != stateReady {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, status, map[string]string{"status": stateNames[state]})
+	})
+}
+
+// Liveness returns a handler answering 200 {"status":"ok"} as long as the
+// process can serve requests at all, including while draining. Point the
+// process supervisor's restart check at it.
+func (s *Server) Liveness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+}
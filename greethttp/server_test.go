@@ -0,0 +1,292 @@
+package greethttp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// startServer serves h through a Server on a loopback port
+func startServer(t *testing.T, h http.Handler, opts ...ServerOption) (s *Server, url string, cancel func(), done <-chan error) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = NewServer(&http.Server{Handler: h}, append([]ServerOption{WithSignals()}, opts...)...)
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- s.Serve(ctx, lis) }()
+	return s, "http://" + lis.Addr().String(), cancel, errc
+}
+
+// get fetches url and returns the status and body
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(b)
+}
+
+// TestServerGracefulShutdown tests draining, readiness and hooks
+func TestServerGracefulShutdown(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var hookRan []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})
+	srv, url, cancel, done := startServer(t, mux,
+		OnShutdown(func(ctx context.Context) error { hookRan = append(hookRan, "cache"); return nil }),
+		OnShutdown(func(ctx context.Context) error { hookRan = append(hookRan, "metrics"); return nil }))
+	mux.Handle("/readyz", srv.Readiness())
+	mux.Handle("/livez", srv.Liveness())
+
+	if status, body := get(t, url+"/readyz"); status != http.StatusOK || !strings.Contains(body, `"ready"`) {
+		t.Errorf("readyz before shutdown = %d %s", status, body)
+	}
+
+	slow := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(url + "/slow")
+		if err != nil {
+			slow <- err.Error()
+			return
+		}
+		b, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		slow <- string(b)
+	}()
+	<-started
+	cancel()
+
+	select {
+	case <-srv.Draining():
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown did not start")
+	}
+	if srv.Ready() {
+		t.Error("Ready during drain")
+	}
+	rec := serve(srv.Readiness(), http.MethodGet, "/readyz", "")
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), `"draining"`) {
+		t.Errorf("readyz during drain = %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(srv.Liveness(), http.MethodGet, "/livez", ""); rec.Code != http.StatusOK {
+		t.Errorf("livez during drain = %d", rec.Code)
+	}
+
+	close(release)
+	if body := <-slow; body != "done" {
+		t.Errorf("in-flight request got %q", body)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Serve = %v", err)
+	}
+	if strings.Join(hookRan, ",") != "cache,metrics" {
+		t.Errorf("hooks ran %v", hookRan)
+	}
+}
+
+// TestServerDrainTimeout tests that stuck requests and failing hooks are reported
+func TestServerDrainTimeout(t *testing.T) {
+	started := make(chan struct{})
+	stuck := make(chan struct{})
+	defer close(stuck)
+	hookErr := errors.New("flush failed")
+	secondHook := false
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-stuck
+	})
+	_, url, cancel, done := startServer(t, h, WithDrainTimeout(50*time.Millisecond),
+		OnShutdown(func(ctx context.Context) error { return hookErr }),
+		OnShutdown(func(ctx context.Context) error { secondHook = true; return nil }))
+
+	go http.Get(url)
+	<-started
+	cancel()
+
+	err := <-done
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve = %v, want drain deadline error", err)
+	}
+	if !secondHook {
+		t.Error("hooks after a failing hook did not run")
+	}
+}
+
+// TestServerListenError tests that a failing server is reported without draining
+func TestServerListenError(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+
+	s := NewServer(&http.Server{Addr: lis.Addr().String(), Handler: New()}, WithSignals())
+	if err := s.Run(context.Background()); err == nil {
+		t.Error("Run on a used address succeeded")
+	}
+	if rec := serve(s.Readiness(), http.MethodGet, "/readyz", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("readyz of a server that never started = %d", rec.Code)
+	}
+}
+
+// signalHelperEnv makes the test binary run TestServerSignalProcess as a
+// server for TestServerSignals to signal
+const signalHelperEnv = "GREETHTTP_SIGNAL_HELPER"
+
+// TestServerSignalProcess is the server process signaled by
+// TestServerSignals. It reports its address, started requests, the start
+// of draining and how Serve returned on stdout, one event per line.
+func TestServerSignalProcess(t *testing.T) {
+	handlerDelay, err := time.ParseDuration(os.Getenv(signalHelperEnv))
+	if err != nil {
+		return
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("started")
+		time.Sleep(handlerDelay)
+		io.WriteString(w, "done")
+	})
+	s := NewServer(&http.Server{Handler: h}, WithDrainTimeout(15*time.Second))
+	go func() {
+		<-s.Draining()
+		fmt.Println("draining")
+	}()
+	fmt.Println("addr", lis.Addr())
+	fmt.Println("served", s.Serve(context.Background(), lis))
+	os.Exit(0)
+}
+
+// TestServerSignals tests that a signal drains the server gracefully and a
+// second signal during the drain kills it
+func TestServerSignals(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals are not supported on Windows")
+	}
+
+	tests := []struct {
+		desc         string
+		handlerDelay time.Duration
+		signals      []os.Signal
+		killed       bool
+	}{
+		{"graceful", 200 * time.Millisecond, []os.Signal{syscall.SIGTERM}, false},
+		{"second signal", 20 * time.Second, []os.Signal{os.Interrupt, os.Interrupt}, true},
+		{"second signal of another kind", 20 * time.Second, []os.Signal{os.Interrupt, syscall.SIGTERM}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			cmd := exec.Command(os.Args[0], "-test.run=^TestServerSignalProcess$")
+			cmd.Env = append(os.Environ(), signalHelperEnv+"="+tt.handlerDelay.String())
+			stdout, err := cmd.StdoutPipe()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cmd.Start(); err != nil {
+				t.Fatal(err)
+			}
+			defer cmd.Process.Kill()
+
+			events := make(chan string, 10)
+			go func() {
+				scanner := bufio.NewScanner(stdout)
+				for scanner.Scan() {
+					events <- scanner.Text()
+				}
+				close(events)
+			}()
+			next := func(prefix string) string {
+				t.Helper()
+				for {
+					select {
+					case line, ok := <-events:
+						if !ok {
+							t.Fatalf("server exited before %q", prefix)
+						}
+						if strings.HasPrefix(line, prefix) {
+							return strings.TrimSpace(strings.TrimPrefix(line, prefix))
+						}
+					case <-time.After(10 * time.Second):
+						t.Fatalf("timed out waiting for %q", prefix)
+					}
+				}
+			}
+
+			addr := next("addr")
+			type response struct {
+				status int
+				err    error
+			}
+			responses := make(chan response, 1)
+			go func() {
+				resp, err := http.Get("http://" + addr)
+				if err != nil {
+					responses <- response{err: err}
+					return
+				}
+				resp.Body.Close()
+				responses <- response{status: resp.StatusCode}
+			}()
+			next("started")
+
+			cmd.Process.Signal(tt.signals[0])
+			next("draining")
+			for _, sig := range tt.signals[1:] {
+				cmd.Process.Signal(sig)
+			}
+
+			exited := make(chan error, 1)
+			go func() {
+				for range events {
+				}
+				exited <- cmd.Wait()
+			}()
+			select {
+			case err = <-exited:
+			case <-time.After(10 * time.Second):
+				t.Fatal("server did not exit")
+			}
+
+			if tt.killed {
+				if err == nil {
+					t.Error("server drained and exited cleanly after a second signal")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("server exited with %v after draining", err)
+			}
+			if r := <-responses; r.err != nil || r.status != http.StatusOK {
+				t.Errorf("in-flight request = %d, %v, want 200", r.status, r.err)
+			}
+		})
+	}
+}
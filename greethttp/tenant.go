@@ -0,0 +1,283 @@
+package greethttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// HeaderAPIKey is the header TenantMux reads API keys from by default.
+const HeaderAPIKey = "X-API-Key"
+
+// ErrTenantConflict is wrapped by the error TenantMux.Handle returns when a
+// route already belongs to another tenant.
+var ErrTenantConflict = errors.New("route belongs to another tenant")
+
+// TenantRoutes selects the requests that belong to a tenant.
+type TenantRoutes struct {
+	APIKeys  []string // values of the API key header
+	Hosts    []string // host names without port, e.g. "greet.acme.example"
+	Prefixes []string // path prefixes such as "/acme", removed before the tenant's handler runs
+}
+
+// TenantOption configures a TenantMux.
+type TenantOption func(*TenantMux)
+
+// WithAPIKeyHeader reads API keys from header instead of HeaderAPIKey.
+func WithAPIKeyHeader(header string) TenantOption {
+	return func(m *TenantMux) {
+		m.keyHeader = header
+	}
+}
+
+// TenantMux serves several tenants, each with its own handler, from one
+// server. A request belongs to the tenant of its API key if it has one,
+// else to the tenant of its Host, else to the tenant with the longest path
+// prefix it falls under; the tenant's prefix is removed from the path
+// either way. Other requests go to the fallback handler, or get a 404
+// ErrorResponse without one.
+//
+// Tenants are independent: replacing or removing one, e.g. after its
+// configuration changed, never affects the routes of the others, and a
+// tenant cannot claim a route another one holds.
+//
+// Example:
+//
+//	m := greethttp.NewTenantMux(greethttp.New())
+//	acme, _ := test.NewGreeter("de")
+//	err := m.Handle("acme", greethttp.TenantRoutes{Hosts: []string{"greet.acme.example"}, Prefixes: []string{"/acme"}},
+//		greethttp.New(greethttp.WithGreeter(acme), greethttp.WithBranding(greethttp.Branding{Title: "ACME"})))
+//
+// Thread Safety:
+//   A TenantMux is safe for concurrent use; tenants may be changed while it
+//   serves requests.
+type TenantMux struct {
+	fallback  http.Handler
+	keyHeader string
+
+	mu       sync.RWMutex
+	tenants  map[string]*tenant
+	keys     map[string]*tenant
+	hosts    map[string]*tenant
+	prefixes []tenantPrefix // longest first
+}
+
+type tenant struct {
+	name    string
+	routes  TenantRoutes
+	handler http.Handler
+}
+
+type tenantPrefix struct {
+	prefix string
+	t      *tenant
+}
+
+// NewTenantMux returns a TenantMux without tenants that sends unmatched
+// requests to fallback, which may be nil.
+func NewTenantMux(fallback http.Handler, opts ...TenantOption) *TenantMux {
+	m := &TenantMux{
+		fallback:  fallback,
+		keyHeader: HeaderAPIKey,
+		tenants:   make(map[string]*tenant),
+		keys:      make(map[string]*tenant),
+		hosts:     make(map[string]*tenant),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Handle serves the tenant called name with h, replacing its previous
+// handler and routes. It returns an error and changes nothing if routes is
+// empty, a prefix does not start with "/", or a route belongs to another
+// tenant.
+func (m *TenantMux) Handle(name string, routes TenantRoutes, h http.Handler) error {
+	routes = TenantRoutes{
+		APIKeys:  append([]string(nil), routes.APIKeys...),
+		Hosts:    append([]string(nil), routes.Hosts...),
+		Prefixes: append([]string(nil), routes.Prefixes...),
+	}
+	for i, host := range routes.Hosts {
+		routes.Hosts[i] = strings.ToLower(host)
+	}
+	for i, p := range routes.Prefixes {
+		if !strings.HasPrefix(p, "/") || strings.TrimRight(p, "/") == "" {
+			return fmt.Errorf("tenant %s: invalid path prefix %q", name, p)
+		}
+		routes.Prefixes[i] = strings.TrimRight(p, "/")
+	}
+	if len(routes.APIKeys)+len(routes.Hosts)+len(routes.Prefixes) == 0 {
+		return fmt.Errorf("tenant %s: no routes", name)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	owner := func(kind, route string, t *tenant) error {
+		if t != nil && t.name != name {
+			return fmt.Errorf("tenant %s: %s %q: %w %s", name, kind, route, ErrTenantConflict, t.name)
+		}
+		return nil
+	}
+	for _, key := range routes.APIKeys {
+		// Keys are secrets; do not echo them in errors.
+		if err := owner("API key", "…"+lastChars(key, 4), m.keys[key]); err != nil {
+			return err
+		}
+	}
+	for _, host := range routes.Hosts {
+		if err := owner("host", host, m.hosts[host]); err != nil {
+			return err
+		}
+	}
+	for _, p := range routes.Prefixes {
+		for _, tp := range m.prefixes {
+			if tp.prefix == p {
+				if err := owner("prefix", p, tp.t); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	m.removeLocked(name)
+	t := &tenant{name: name, routes: routes, handler: h}
+	m.tenants[name] = t
+	for _, key := range routes.APIKeys {
+		m.keys[key] = t
+	}
+	for _, host := range routes.Hosts {
+		m.hosts[host] = t
+	}
+	for _, p := range routes.Prefixes {
+		m.prefixes = append(m.prefixes, tenantPrefix{p, t})
+	}
+	sort.SliceStable(m.prefixes, func(i, j int) bool {
+		return len(m.prefixes[i].prefix) > len(m.prefixes[j].prefix)
+	})
+	return nil
+}
+
+// Remove stops serving the tenant called name.
+func (m *TenantMux) Remove(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.removeLocked(name)
+}
+
+func (m *TenantMux) removeLocked(name string) {
+	t := m.tenants[name]
+	if t == nil {
+		return
+	}
+	delete(m.tenants, name)
+	for _, key := range t.routes.APIKeys {
+		delete(m.keys, key)
+	}
+	for _, host := range t.routes.Hosts {
+		delete(m.hosts, host)
+	}
+	kept := m.prefixes[:0]
+	for _, tp := range m.prefixes {
+		if tp.t != t {
+			kept = append(kept, tp)
+		}
+	}
+	m.prefixes = kept
+}
+
+// Tenants returns the sorted names of the tenants served.
+func (m *TenantMux) Tenants() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.tenants))
+	for name := range m.tenants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ServeHTTP implements http.Handler.
+func (m *TenantMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t := m.match(r)
+	if t == nil {
+		if m.fallback != nil {
+			m.fallback.ServeHTTP(w, r)
+			return
+		}
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "unknown tenant")
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), tenantKey{}, t.name)
+	for _, p := range t.routes.Prefixes {
+		if r.URL.Path == p {
+			// Relative links on the tenant's pages need the trailing slash.
+			u := *r.URL
+			u.Path = p + "/"
+			http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, p+"/") {
+			u := *r.URL
+			u.Path = strings.TrimPrefix(r.URL.Path, p)
+			u.RawPath = ""
+			if strings.HasPrefix(r.URL.RawPath, p+"/") {
+				u.RawPath = strings.TrimPrefix(r.URL.RawPath, p)
+			}
+			r = r.WithContext(ctx)
+			r.URL = &u
+			t.handler.ServeHTTP(w, r)
+			return
+		}
+	}
+	t.handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// match returns the tenant r belongs to, or nil.
+func (m *TenantMux) match(r *http.Request) *tenant {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if key := r.Header.Get(m.keyHeader); key != "" {
+		if t := m.keys[key]; t != nil {
+			return t
+		}
+	}
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if t := m.hosts[strings.ToLower(host)]; t != nil {
+		return t
+	}
+	for _, tp := range m.prefixes {
+		if r.URL.Path == tp.prefix || strings.HasPrefix(r.URL.Path, tp.prefix+"/") {
+			return tp.t
+		}
+	}
+	return nil
+}
+
+type tenantKey struct{}
+
+// TenantFromContext returns the name of the tenant TenantMux routed the
+// request with context ctx to, or "" outside a tenant.
+func TenantFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(tenantKey{}).(string)
+	return name
+}
+
+// lastChars returns the last n bytes of s, or s if it is shorter.
+func lastChars(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[len(s)-n:]
+}
@@ -0,0 +1,147 @@
+package greethttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zhangbaodong/test"
+)
+
+// tenantEcho returns a handler that writes the tenant, label and path it sees
+func tenantEcho(label string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(TenantFromContext(r.Context()) + " " + label + " " + r.URL.Path))
+	})
+}
+
+// TestTenantMuxRouting tests tenant resolution by API key, host and prefix
+func TestTenantMuxRouting(t *testing.T) {
+	m := NewTenantMux(tenantEcho("fallback"))
+	for name, routes := range map[string]TenantRoutes{
+		"acme":    {Hosts: []string{"Greet.ACME.example"}, Prefixes: []string{"/acme/"}},
+		"globex":  {APIKeys: []string{"k-globex"}, Prefixes: []string{"/globex"}},
+		"globex2": {Prefixes: []string{"/globex/v2"}},
+	} {
+		if err := m.Handle(name, routes, tenantEcho(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		host, key, target string
+		expected          string
+	}{
+		{"greet.acme.example:8080", "", "/api/greet", "acme acme /api/greet"},
+		{"greet.acme.example", "", "/acme/api/greet", "acme acme /api/greet"},
+		{"localhost", "", "/acme/api/greet", "acme acme /api/greet"},
+		{"greet.acme.example", "k-globex", "/api/greet", "globex globex /api/greet"},
+		{"localhost", "", "/globex/v2/health", "globex2 globex2 /health"},
+		{"localhost", "", "/globex/health", "globex globex /health"},
+		{"localhost", "", "/globexx", " fallback /globexx"},
+		{"localhost", "unknown", "/api/greet", " fallback /api/greet"},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		r.Host = tt.host
+		if tt.key != "" {
+			r.Header.Set(HeaderAPIKey, tt.key)
+		}
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, r)
+		if w.Body.String() != tt.expected {
+			t.Errorf("%s %s key %q = %q, want %q", tt.host, tt.target, tt.key, w.Body.String(), tt.expected)
+		}
+	}
+
+	w := serve(m, http.MethodGet, "/acme?name=Al", "")
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/acme/?name=Al" {
+		t.Errorf("prefix without slash = %d %q", w.Code, w.Header().Get("Location"))
+	}
+	if w := serve(NewTenantMux(nil), http.MethodGet, "/", ""); w.Code != http.StatusNotFound {
+		t.Errorf("no tenant without fallback = %d", w.Code)
+	}
+}
+
+// TestTenantMuxIsolation tests that tenants cannot take or disturb each other's routes
+func TestTenantMuxIsolation(t *testing.T) {
+	m := NewTenantMux(nil, WithAPIKeyHeader("X-Tenant-Key"))
+	if err := m.Handle("acme", TenantRoutes{APIKeys: []string{"secret-acme"}, Hosts: []string{"acme.example"}}, tenantEcho("v1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Handle("globex", TenantRoutes{Prefixes: []string{"/globex"}}, tenantEcho("v1")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		routes   TenantRoutes
+		conflict bool
+	}{
+		{TenantRoutes{Hosts: []string{"ACME.example"}}, true},
+		{TenantRoutes{APIKeys: []string{"secret-acme"}}, true},
+		{TenantRoutes{Prefixes: []string{"/globex/"}}, true},
+		{TenantRoutes{}, false},
+		{TenantRoutes{Prefixes: []string{"globex"}}, false},
+		{TenantRoutes{Prefixes: []string{"/"}}, false},
+	}
+	for _, tt := range tests {
+		err := m.Handle("initech", tt.routes, tenantEcho("bad"))
+		if err == nil || errors.Is(err, ErrTenantConflict) != tt.conflict {
+			t.Errorf("Handle(%+v) = %v, conflict %v", tt.routes, err, tt.conflict)
+		}
+		if err != nil && strings.Contains(err.Error(), "secret") {
+			t.Errorf("error reveals an API key: %v", err)
+		}
+	}
+	if names := strings.Join(m.Tenants(), ","); names != "acme,globex" {
+		t.Errorf("Tenants() = %s", names)
+	}
+
+	// Replacing a tenant swaps its routes without touching the others.
+	if err := m.Handle("acme", TenantRoutes{Hosts: []string{"greet.acme.example"}}, tenantEcho("v2")); err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(http.MethodGet, "/globex/x", nil)
+	r.Header.Set("X-Tenant-Key", "secret-acme")
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, r)
+	if w.Body.String() != "globex v1 /x" {
+		t.Errorf("dropped key still routes: %q", w.Body.String())
+	}
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Host = "greet.acme.example"
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, r)
+	if w.Body.String() != "acme v2 /" {
+		t.Errorf("replaced tenant = %q", w.Body.String())
+	}
+
+	m.Remove("acme")
+	if err := m.Handle("initech", TenantRoutes{Hosts: []string{"acme.example"}}, tenantEcho("v1")); err != nil {
+		t.Errorf("route of removed tenant: %v", err)
+	}
+}
+
+// TestTenantMuxHandlers tests serving differently configured Handlers per tenant
+func TestTenantMuxHandlers(t *testing.T) {
+	de, err := test.NewStrategy(test.StrategyFormal, "de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewTenantMux(New())
+	m.Handle("acme", TenantRoutes{Prefixes: []string{"/acme"}},
+		New(WithGreeter(de), WithDefaultName("Kunde"), WithBranding(Branding{Title: "ACME"})))
+
+	if w := serve(m, http.MethodGet, "/acme/api/simple", ""); w.Body.String() != "Guten Tag, Kunde" {
+		t.Errorf("tenant simple = %q", w.Body.String())
+	}
+	if w := serve(m, http.MethodGet, "/api/simple", ""); w.Body.String() != "Hi, Guest" {
+		t.Errorf("fallback simple = %q", w.Body.String())
+	}
+	if w := serve(m, http.MethodGet, "/acme/", ""); !strings.Contains(w.Body.String(), "<h1>ACME</h1>") {
+		t.Errorf("tenant page = %d, want ACME branding", w.Code)
+	}
+}
@@ -0,0 +1,151 @@
+package greethttp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+)
+
+// W3C Trace Context headers.
+const (
+	HeaderTraceparent = "traceparent"
+	HeaderTracestate  = "tracestate"
+)
+
+// ErrInvalidTraceparent is returned by ParseTraceparent for malformed headers.
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// flagSampled is the "sampled" bit of the trace flags.
+const flagSampled = 0x01
+
+// TraceContext is the W3C Trace Context of a request: the trace it belongs
+// to and the span serving it. IDs are lowercase hex.
+type TraceContext struct {
+	TraceID  string // 32 hex digits
+	SpanID   string // 16 hex digits
+	ParentID string // the caller's span; empty when the trace started here
+	Flags    byte
+	State    string // tracestate, passed through unchanged
+}
+
+// ParseTraceparent parses a traceparent header. The returned context's
+// SpanID is the caller's span; use it as the parent of new spans.
+//
+// Example:
+//
+//	tc, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+//	// tc.TraceID == "4bf92f3577b34da6a3ce929d0e0e4736", tc.Sampled() == true
+func ParseTraceparent(s string) (TraceContext, error) {
+	// version "-" trace-id "-" parent-id "-" flags, with fields appended by
+	// future versions after a further "-".
+	if len(s) < 55 || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return TraceContext{}, ErrInvalidTraceparent
+	}
+	version, ok := parseHexByte(s[0:2])
+	if !ok || version == 0xff || (version == 0 && len(s) != 55) || (len(s) > 55 && s[55] != '-') {
+		return TraceContext{}, ErrInvalidTraceparent
+	}
+	flags, ok := parseHexByte(s[53:55])
+	if !ok {
+		return TraceContext{}, ErrInvalidTraceparent
+	}
+	tc := TraceContext{TraceID: s[3:35], SpanID: s[36:52], Flags: flags}
+	if !isTraceID(tc.TraceID) || !isTraceID(tc.SpanID) {
+		return TraceContext{}, ErrInvalidTraceparent
+	}
+	return tc, nil
+}
+
+// Traceparent formats tc as a version 00 traceparent header.
+func (tc TraceContext) Traceparent() string {
+	return "00-" + tc.TraceID + "-" + tc.SpanID + "-" + hex.EncodeToString([]byte{tc.Flags})
+}
+
+// Sampled reports whether the caller recorded the trace.
+func (tc TraceContext) Sampled() bool {
+	return tc.Flags&flagSampled != 0
+}
+
+// Inject sets the traceparent and tracestate headers of an outgoing request
+// so that the next service continues the trace.
+func (tc TraceContext) Inject(h http.Header) {
+	h.Set(HeaderTraceparent, tc.Traceparent())
+	if tc.State != "" {
+		h.Set(HeaderTracestate, tc.State)
+	}
+}
+
+// traceFromRequest continues the trace of r's traceparent header in a new
+// span, or starts a new trace if the header is missing or malformed.
+func traceFromRequest(r *http.Request) TraceContext {
+	parent, err := ParseTraceparent(r.Header.Get(HeaderTraceparent))
+	if err != nil {
+		return TraceContext{TraceID: randomHex(16), SpanID: randomHex(8)}
+	}
+	return TraceContext{
+		TraceID:  parent.TraceID,
+		SpanID:   randomHex(8),
+		ParentID: parent.SpanID,
+		Flags:    parent.Flags,
+		State:    r.Header.Get(HeaderTracestate),
+	}
+}
+
+type traceKey struct{}
+
+// ContextWithTrace returns a copy of ctx carrying tc.
+func ContextWithTrace(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceKey{}, tc)
+}
+
+// TraceFromContext returns the TraceContext stored in ctx by Logging or
+// ContextWithTrace.
+func TraceFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceKey{}).(TraceContext)
+	return tc, ok
+}
+
+// isTraceID reports whether s is lowercase hex and not all zeros, as the
+// spec requires of trace and span IDs.
+func isTraceID(s string) bool {
+	nonzero := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '0':
+		case '1' <= c && c <= '9', 'a' <= c && c <= 'f':
+			nonzero = true
+		default:
+			return false
+		}
+	}
+	return nonzero
+}
+
+// parseHexByte parses two lowercase hex digits.
+func parseHexByte(s string) (byte, bool) {
+	var b byte
+	for i := 0; i < 2; i++ {
+		c := s[i]
+		switch {
+		case '0' <= c && c <= '9':
+			b = b<<4 | (c - '0')
+		case 'a' <= c && c <= 'f':
+			b = b<<4 | (c - 'a' + 10)
+		default:
+			return 0, false
+		}
+	}
+	return b, true
+}
+
+// randomHex returns n random bytes as hex.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic("greethttp: crypto/rand: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,70 @@
+package greethttp
+
+import (
+	"net/http"
+	"testing"
+)
+
+// TestParseTraceparent tests parsing of W3C traceparent headers
+func TestParseTraceparent(t *testing.T) {
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+	tests := []struct {
+		header  string
+		valid   bool
+		sampled bool
+	}{
+		{"00-" + traceID + "-" + spanID + "-01", true, true},
+		{"00-" + traceID + "-" + spanID + "-00", true, false},
+		{"01-" + traceID + "-" + spanID + "-01-future", true, true},
+		{"01-" + traceID + "-" + spanID + "-01", true, true},
+		{"", false, false},
+		{"00-" + traceID + "-" + spanID + "-01-extra", false, false},
+		{"01-" + traceID + "-" + spanID + "-01x", false, false},
+		{"ff-" + traceID + "-" + spanID + "-01", false, false},
+		{"00-00000000000000000000000000000000-" + spanID + "-01", false, false},
+		{"00-" + traceID + "-0000000000000000-01", false, false},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-" + spanID + "-01", false, false},
+		{"00_" + traceID + "-" + spanID + "-01", false, false},
+		{"00-" + traceID + "-" + spanID + "-0g", false, false},
+	}
+
+	for _, tt := range tests {
+		tc, err := ParseTraceparent(tt.header)
+		if (err == nil) != tt.valid {
+			t.Errorf("ParseTraceparent(%q) error = %v, want valid %v", tt.header, err, tt.valid)
+			continue
+		}
+		if !tt.valid {
+			continue
+		}
+		if tc.TraceID != traceID || tc.SpanID != spanID || tc.Sampled() != tt.sampled {
+			t.Errorf("ParseTraceparent(%q) = %+v", tt.header, tc)
+		}
+	}
+}
+
+// TestTraceContextInject tests formatting of outgoing trace headers
+func TestTraceContextInject(t *testing.T) {
+	tc := TraceContext{
+		TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:  "00f067aa0ba902b7",
+		Flags:   0x01,
+		State:   "vendor=abc",
+	}
+	h := http.Header{}
+	tc.Inject(h)
+	if got := h.Get(HeaderTraceparent); got != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("traceparent = %q", got)
+	}
+	if got := h.Get(HeaderTracestate); got != "vendor=abc" {
+		t.Errorf("tracestate = %q", got)
+	}
+
+	back, err := ParseTraceparent(tc.Traceparent())
+	if err != nil || back.TraceID != tc.TraceID || back.SpanID != tc.SpanID || back.Flags != tc.Flags {
+		t.Errorf("round trip = %+v, %v", back, err)
+	}
+}
@@ -0,0 +1,234 @@
+package test
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultGroupThreshold is the largest group SayHiAll greets by name;
+// larger groups get the locale's "greeting.everyone" message.
+const DefaultGroupThreshold = 5
+
+// Catalog keys for group greetings. List patterns follow CLDR: "{0}" and
+// "{1}" stand for the two parts being joined. Like the addressing rules,
+// they are only honored when defined in the language of the greeting;
+// otherwise names are joined with ", ".
+const (
+	// MessageEveryone holds the greeting for groups above the threshold,
+	// e.g. "Hi everyone". It is a template rendered like the greeting.
+	MessageEveryone = "greeting.everyone"
+
+	// MessageListTwo joins a list of exactly two names, e.g. "{0} and {1}".
+	MessageListTwo = "list.two"
+
+	// MessageListStart, MessageListMiddle and MessageListEnd join the
+	// first, inner and last two names of longer lists, e.g. "{0}, {1}",
+	// "{0}, {1}" and "{0} and {1}".
+	MessageListStart  = "list.start"
+	MessageListMiddle = "list.middle"
+	MessageListEnd    = "list.end"
+
+	// MessageListEndOxford replaces MessageListEnd when the serial comma
+	// is enabled with WithOxfordComma, e.g. "{0}, and {1}".
+	MessageListEndOxford = "list.end.oxford"
+
+	// MessageOthers summarizes the names left out by WithListLimit, e.g.
+	// "{0} others", with "{0}" standing for the count. It is pluralized:
+	// "list.others.one", "list.others.few" and so on are looked up by
+	// CardinalPlural, falling back to "list.others.other".
+	MessageOthers = "list.others"
+)
+
+// groupFormat is how a locale greets a group.
+type groupFormat struct {
+	lang                    string
+	two, start, middle, end string
+	everyone                *Template
+	others                  map[PluralCategory]string
+	threshold, limit        int
+}
+
+func loadGroupFormat(c *Catalog, tag, lang string, cfg *greeterConfig) (groupFormat, error) {
+	f := groupFormat{
+		lang:      lang,
+		two:       "{0}, {1}",
+		start:     "{0}, {1}",
+		middle:    "{0}, {1}",
+		end:       "{0}, {1}",
+		threshold: DefaultGroupThreshold,
+		limit:     cfg.listLimit,
+	}
+	if cfg.groupThreshold != nil {
+		f.threshold = *cfg.groupThreshold
+	}
+
+	endKey := MessageListEnd
+	if cfg.oxfordComma {
+		if _, ok := lookupLanguage(c, tag, lang, MessageListEndOxford); ok {
+			endKey = MessageListEndOxford
+		}
+	}
+	for _, p := range []struct {
+		key string
+		dst *string
+	}{
+		{MessageListTwo, &f.two},
+		{MessageListStart, &f.start},
+		{MessageListMiddle, &f.middle},
+		{endKey, &f.end},
+	} {
+		if msg, ok := lookupLanguage(c, tag, lang, p.key); ok {
+			if !strings.Contains(msg, "{0}") || !strings.Contains(msg, "{1}") {
+				return f, fmt.Errorf("%q message for %s must contain {0} and {1}", p.key, lang)
+			}
+			*p.dst = msg
+		}
+	}
+
+	for cat := PluralZero; cat <= PluralOther; cat++ {
+		key := MessageOthers + "." + cat.String()
+		if msg, ok := lookupLanguage(c, tag, lang, key); ok {
+			if !strings.Contains(msg, "{0}") {
+				return f, fmt.Errorf("%q message for %s must contain {0}", key, lang)
+			}
+			if f.others == nil {
+				f.others = make(map[PluralCategory]string)
+			}
+			f.others[cat] = msg
+		}
+	}
+
+	if src, ok := lookupLanguage(c, tag, lang, MessageEveryone); ok {
+		t, err := ParseTemplate(src)
+		if err != nil {
+			return f, fmt.Errorf("%q message for %s: %w", MessageEveryone, lang, err)
+		}
+		f.everyone = t
+	}
+	return f, nil
+}
+
+// join formats names as a list, summarizing those beyond the list limit.
+func (f *groupFormat) join(names []string) string {
+	if f.limit > 0 && len(names) > f.limit {
+		if others, ok := f.othersFor(len(names) - f.limit); ok {
+			names = append(names[:f.limit:f.limit], others)
+		}
+	}
+
+	switch len(names) {
+	case 0:
+		return ""
+	case 1:
+		return names[0]
+	case 2:
+		return applyListPattern(f.two, names[0], names[1])
+	}
+	n := len(names)
+	s := applyListPattern(f.end, names[n-2], names[n-1])
+	for i := n - 3; i > 0; i-- {
+		s = applyListPattern(f.middle, names[i], s)
+	}
+	return applyListPattern(f.start, names[0], s)
+}
+
+// othersFor renders the summary of n left-out names.
+func (f *groupFormat) othersFor(n int) (string, bool) {
+	msg, ok := f.others[CardinalPlural(f.lang, n)]
+	if !ok {
+		if msg, ok = f.others[PluralOther]; !ok {
+			return "", false
+		}
+	}
+	return applyListPattern(msg, strconv.Itoa(n), ""), true
+}
+
+// applyListPattern substitutes a for "{0}" and b for "{1}" in a single pass,
+// so braces inside names are never interpreted.
+func applyListPattern(pattern, a, b string) string {
+	var sb strings.Builder
+	sb.Grow(len(pattern) + len(a) + len(b))
+	for {
+		i := strings.IndexByte(pattern, '{')
+		if i < 0 || i+3 > len(pattern) {
+			sb.WriteString(pattern)
+			return sb.String()
+		}
+		switch pattern[i : i+3] {
+		case "{0}":
+			sb.WriteString(pattern[:i])
+			sb.WriteString(a)
+			pattern = pattern[i+3:]
+		case "{1}":
+			sb.WriteString(pattern[:i])
+			sb.WriteString(b)
+			pattern = pattern[i+3:]
+		default:
+			sb.WriteString(pattern[:i+1])
+			pattern = pattern[i+1:]
+		}
+	}
+}
+
+// WithGroupThreshold sets the largest group SayHiAll greets by name; larger
+// groups get the "greeting.everyone" message. Zero or less disables it.
+// The default is DefaultGroupThreshold.
+func WithGroupThreshold(n int) Option {
+	return func(cfg *greeterConfig) {
+		cfg.groupThreshold = &n
+	}
+}
+
+// WithListLimit makes SayHiAll name at most n people and summarize the
+// rest, e.g. "Hi, Alice, Bob and 3 others". Zero or less, the default,
+// names everyone up to the group threshold.
+func WithListLimit(n int) Option {
+	return func(cfg *greeterConfig) {
+		cfg.listLimit = n
+	}
+}
+
+// WithOxfordComma enables the serial comma before the final conjunction,
+// e.g. "Alice, Bob, and Charlie", in locales that define one.
+func WithOxfordComma(on bool) Option {
+	return func(cfg *greeterConfig) {
+		cfg.oxfordComma = on
+	}
+}
+
+// SayHiAll greets a group of people in one sentence, e.g.
+// "Hi, Alice, Bob and Charlie". Names are normalized and blank ones
+// dropped; an empty group, or one larger than DefaultGroupThreshold, is
+// greeted with "Hi everyone".
+//
+// Example:
+//
+//	fmt.Println(SayHiAll([]string{"Alice", "Bob"})) // Output: Hi, Alice and Bob
+//
+// Thread Safety:
+//   This function is safe for concurrent use by multiple goroutines.
+func SayHiAll(names []string) string {
+	return defaultGreeter().SayHiAll(names)
+}
+
+// SayHiAll greets a group of people in one sentence using the locale's list
+// conjunctions, e.g. "Hallo, Anna, Ben und Clara". See WithGroupThreshold,
+// WithListLimit and WithOxfordComma.
+func (g *LocaleGreeter) SayHiAll(names []string) string {
+	list := make([]string, 0, len(names))
+	for _, name := range names {
+		if name = NormalizeName(name); name != "" {
+			list = append(list, name)
+		}
+	}
+
+	v := Values{}
+	t := g.prepare(&v)
+	everyone := len(list) == 0 || (g.group.threshold > 0 && len(list) > g.group.threshold)
+	if everyone && g.group.everyone != nil {
+		return g.group.everyone.Render(&v)
+	}
+	v.Name = g.group.join(list)
+	return t.Render(&v)
+}
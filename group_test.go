@@ -0,0 +1,168 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestSayHiAll tests English group greetings with the default options
+func TestSayHiAll(t *testing.T) {
+	tests := []struct {
+		names    []string
+		expected string
+	}{
+		{[]string{"Alice"}, "Hi, Alice"},
+		{[]string{"Alice", "Bob"}, "Hi, Alice and Bob"},
+		{[]string{"Alice", "Bob", "Charlie"}, "Hi, Alice, Bob and Charlie"},
+		{[]string{"Alice", "Bob", "Charlie", "Dave"}, "Hi, Alice, Bob, Charlie and Dave"},
+		{[]string{"  Alice ", "", "\t", "Bob"}, "Hi, Alice and Bob"},
+		{[]string{"{0}", "{1}"}, "Hi, {0} and {1}"},
+		{nil, "Hi everyone"},
+		{[]string{"A", "B", "C", "D", "E"}, "Hi, A, B, C, D and E"},
+		{[]string{"A", "B", "C", "D", "E", "F"}, "Hi everyone"},
+	}
+
+	for _, tt := range tests {
+		if result := SayHiAll(tt.names); result != tt.expected {
+			t.Errorf("SayHiAll(%q) = %q, want %q", tt.names, result, tt.expected)
+		}
+	}
+}
+
+// TestGreeterSayHiAll tests locale list patterns and group options
+func TestGreeterSayHiAll(t *testing.T) {
+	three := []string{"Alice", "Bob", "Charlie"}
+	tests := []struct {
+		tag      string
+		opts     []Option
+		names    []string
+		expected string
+	}{
+		{"en", []Option{WithOxfordComma(true)}, three, "Hi, Alice, Bob, and Charlie"},
+		{"en", []Option{WithOxfordComma(true)}, three[:2], "Hi, Alice and Bob"},
+		{"de", []Option{WithOxfordComma(true)}, three, "Hallo, Alice, Bob und Charlie"},
+		{"es", nil, three, "Hola, Alice, Bob y Charlie"},
+		{"pt-BR", nil, three, "Oi, Alice, Bob e Charlie"},
+		{"pt-BR", nil, nil, "Oi, pessoal"},
+		{"ja", nil, three, "こんにちは、Alice、Bob、Charlie"},
+		{"zh", nil, three, "你好，Alice、Bob和Charlie"},
+		{"en", []Option{WithGroupThreshold(2)}, three, "Hi everyone"},
+		{"en", []Option{WithGroupThreshold(0)}, []string{"A", "B", "C", "D", "E", "F"}, "Hi, A, B, C, D, E and F"},
+		{"en", []Option{WithListLimit(2)}, three, "Hi, Alice, Bob and 1 other"},
+		{"en", []Option{WithListLimit(1)}, three, "Hi, Alice and 2 others"},
+		{"en", []Option{WithListLimit(3)}, three, "Hi, Alice, Bob and Charlie"},
+		{"fr", []Option{WithListLimit(1)}, three, "Salut, Alice et 2 autres"},
+		{"ja", []Option{WithListLimit(1)}, three, "こんにちは、Alice、他2名"},
+		{"en", []Option{WithFormality(Formal)}, three[:2], "Good day, Alice and Bob"},
+		{"en", []Option{WithTimeOfDay(ClockFunc(func() time.Time { return at(8, 0) }))}, three[:2], "Good morning, Alice and Bob"},
+	}
+
+	for _, tt := range tests {
+		g, err := NewGreeter(tt.tag, tt.opts...)
+		if err != nil {
+			t.Fatalf("NewGreeter(%q) error: %v", tt.tag, err)
+		}
+		if result := g.SayHiAll(tt.names); result != tt.expected {
+			t.Errorf("%s SayHiAll(%q) = %q, want %q", tt.tag, tt.names, result, tt.expected)
+		}
+	}
+}
+
+// TestSayHiAllPlurals tests CLDR plural forms in the "others" summary
+func TestSayHiAllPlurals(t *testing.T) {
+	tests := []struct {
+		tag      string
+		others   int
+		expected string
+	}{
+		{"ru", 1, "Привет, A и ещё 1 человек"},
+		{"ru", 3, "Привет, A и ещё 3 человека"},
+		{"ru", 5, "Привет, A и ещё 5 человек"},
+		{"ru", 21, "Привет, A и ещё 21 человек"},
+		{"pl", 1, "Cześć, A i 1 inna osoba"},
+		{"pl", 4, "Cześć, A i 4 inne osoby"},
+		{"pl", 12, "Cześć, A i 12 innych osób"},
+		{"pl", 22, "Cześć, A i 22 inne osoby"},
+		{"de", 1, "Hallo, A und 1 weitere Person"},
+		{"de", 2, "Hallo, A und 2 weitere Personen"},
+	}
+
+	for _, tt := range tests {
+		g, err := NewGreeter(tt.tag, WithListLimit(1), WithGroupThreshold(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := []string{"A"}
+		for i := 0; i < tt.others; i++ {
+			names = append(names, fmt.Sprint("N", i))
+		}
+		if result := g.SayHiAll(names); result != tt.expected {
+			t.Errorf("%s with %d others = %q, want %q", tt.tag, tt.others, result, tt.expected)
+		}
+	}
+}
+
+// TestCardinalPlural tests the CLDR plural rules
+func TestCardinalPlural(t *testing.T) {
+	tests := []struct {
+		tag      string
+		n        int
+		expected PluralCategory
+	}{
+		{"en", 0, PluralOther},
+		{"en", 1, PluralOne},
+		{"en-GB", 2, PluralOther},
+		{"fr", 0, PluralOne},
+		{"fr", 1, PluralOne},
+		{"fr", 2000000, PluralMany},
+		{"pt_BR", 0, PluralOne},
+		{"es", 1000000, PluralMany},
+		{"ru", 11, PluralMany},
+		{"ru", 22, PluralFew},
+		{"ru", 101, PluralOne},
+		{"pl", 21, PluralMany},
+		{"cs", 3, PluralFew},
+		{"ja", 1, PluralOther},
+		{"xx", 1, PluralOther},
+		{"en", -1, PluralOne},
+	}
+
+	for _, tt := range tests {
+		if result := CardinalPlural(tt.tag, tt.n); result != tt.expected {
+			t.Errorf("CardinalPlural(%q, %d) = %v, want %v", tt.tag, tt.n, result, tt.expected)
+		}
+	}
+}
+
+// TestSayHiAllCatalog tests fallback and validation of custom list patterns
+func TestSayHiAllCatalog(t *testing.T) {
+	c := DefaultCatalog()
+	if err := c.Set("sv", MessageGreeting, "Hej, {name}"); err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGreeter("sv", WithCatalog(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// English conjunctions must not leak into Swedish.
+	if result := g.SayHiAll([]string{"Anna", "Bo"}); result != "Hej, Anna, Bo" {
+		t.Errorf("SayHiAll without list patterns = %q, want %q", result, "Hej, Anna, Bo")
+	}
+
+	if err := c.Set("sv", MessageListTwo, "{0} och"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewGreeter("sv", WithCatalog(c)); err == nil {
+		t.Error("NewGreeter accepted a list pattern without {1}")
+	}
+}
+
+// BenchmarkSayHiAll benchmarks a three-name group greeting
+func BenchmarkSayHiAll(b *testing.B) {
+	names := []string{"Alice", "Bob", "Charlie"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		SayHiAll(names)
+	}
+}
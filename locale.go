@@ -0,0 +1,106 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultLocale is the language tag every fallback chain ends in. It is the
+// locale used by the package-level SayHi family.
+const DefaultLocale = "en"
+
+// CanonicalTag parses a BCP 47 language tag and returns it in canonical
+// form: lower-case language, title-case script, upper-case region and
+// lower-case variants. Underscores are accepted as separators so that
+// POSIX-style locale names such as "pt_BR" work as well.
+//
+// Example:
+//
+//	tag, _ := CanonicalTag("zh_hant_tw")
+//	fmt.Println(tag) // Output: zh-Hant-TW
+func CanonicalTag(tag string) (string, error) {
+	if tag == "" {
+		return "", fmt.Errorf("invalid language tag %q: empty", tag)
+	}
+
+	parts := strings.FieldsFunc(tag, func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) == 0 || strings.Count(tag, "-")+strings.Count(tag, "_") != len(parts)-1 {
+		return "", fmt.Errorf("invalid language tag %q: empty subtag", tag)
+	}
+
+	lang := parts[0]
+	if !isAlpha(lang) || len(lang) < 2 || len(lang) > 8 || len(lang) == 4 {
+		return "", fmt.Errorf("invalid language tag %q: bad language subtag %q", tag, lang)
+	}
+	parts[0] = strings.ToLower(lang)
+
+	for i := 1; i < len(parts); i++ {
+		p := parts[i]
+		if len(p) > 8 || !isAlnum(p) {
+			return "", fmt.Errorf("invalid language tag %q: bad subtag %q", tag, p)
+		}
+		switch {
+		case i == 1 && len(p) == 4 && isAlpha(p):
+			// Script, e.g. "Hant".
+			parts[i] = strings.ToUpper(p[:1]) + strings.ToLower(p[1:])
+		case i <= 2 && (len(p) == 2 && isAlpha(p) || len(p) == 3 && isDigit(p)):
+			// Region, e.g. "BR" or "419".
+			parts[i] = strings.ToUpper(p)
+		default:
+			parts[i] = strings.ToLower(p)
+		}
+	}
+
+	return strings.Join(parts, "-"), nil
+}
+
+// FallbackChain returns the lookup order for tag, from most to least
+// specific, always ending in DefaultLocale. The tag is expected to be in
+// canonical form.
+//
+// Example:
+//
+//	FallbackChain("pt-BR") // []string{"pt-BR", "pt", "en"}
+func FallbackChain(tag string) []string {
+	chain := make([]string, 0, 4)
+	for tag != "" {
+		chain = append(chain, tag)
+		i := strings.LastIndexByte(tag, '-')
+		if i < 0 {
+			break
+		}
+		tag = tag[:i]
+	}
+	if len(chain) == 0 || chain[len(chain)-1] != DefaultLocale {
+		chain = append(chain, DefaultLocale)
+	}
+	return chain
+}
+
+func isAlpha(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i] | 0x20
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigit(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isAlpha(s[i:i+1]) && !isDigit(s[i:i+1]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,199 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxNameLength is the maximum length of a name accepted by ValidateName,
+// counted in user-perceived characters (grapheme clusters), so "José" is
+// four characters whether or not its accent is precomposed.
+const MaxNameLength = 100
+
+// Errors reported by ValidateName and SayHiStrict. They are wrapped in a
+// *NameError; use errors.Is to test for them.
+var (
+	ErrEmptyName    = errors.New("name is empty")
+	ErrControlChars = errors.New("name contains control characters")
+	ErrTooLong      = errors.New("name is too long")
+)
+
+// NameError records a name that failed validation and why.
+type NameError struct {
+	Name string // the name as passed by the caller
+	Err  error  // one of ErrEmptyName, ErrControlChars or ErrTooLong
+}
+
+func (e *NameError) Error() string {
+	return fmt.Sprintf("invalid name %q: %v", e.Name, e.Err)
+}
+
+func (e *NameError) Unwrap() error {
+	return e.Err
+}
+
+// NormalizeName returns name in the canonical form greetings expect:
+// Unicode NFC, control characters removed, runs of whitespace collapsed to
+// a single space, and no leading or trailing whitespace.
+//
+// Example:
+//
+//	NormalizeName("  José \t Müller\x00\n") // "José Müller"
+func NormalizeName(name string) string {
+	return normalize(name, true)
+}
+
+// ValidateName reports whether name is acceptable for a greeting as is. It
+// returns a *NameError wrapping ErrEmptyName if the name is blank,
+// ErrControlChars if it contains control characters (including tabs and
+// newlines) or invalid UTF-8, and ErrTooLong if it is longer than
+// MaxNameLength characters. Names returned by NormalizeName only fail for
+// being empty or too long.
+func ValidateName(name string) error {
+	if err := validateName(name); err != nil {
+		return &NameError{Name: name, Err: err}
+	}
+	return nil
+}
+
+// SayHiStrict is like SayHi but normalizes the name first and rejects it
+// instead of greeting it if it is invalid. Unlike NormalizeName, control
+// characters other than whitespace are reported rather than removed.
+//
+// Example:
+//
+//	greeting, err := SayHiStrict("  Alice ")  // "Hi, Alice", nil
+//	_, err = SayHiStrict("Alice\x00")         // errors.Is(err, ErrControlChars)
+func SayHiStrict(name string) (string, error) {
+	n := normalize(name, false)
+	if err := validateName(n); err != nil {
+		return "", &NameError{Name: name, Err: err}
+	}
+	return SayHi(n), nil
+}
+
+// SayHiStrict is like SayHi but normalizes and validates the name first.
+// See the package-level SayHiStrict.
+func (g *LocaleGreeter) SayHiStrict(name string) (string, error) {
+	n := normalize(name, false)
+	if err := validateName(n); err != nil {
+		return "", &NameError{Name: name, Err: err}
+	}
+	return g.SayHi(n), nil
+}
+
+func validateName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return ErrEmptyName
+	}
+	for i := 0; i < len(name); {
+		r, size := utf8.DecodeRuneInString(name[i:])
+		if isControl(r, size) {
+			return ErrControlChars
+		}
+		i += size
+	}
+	if graphemeLen(name) > MaxNameLength {
+		return ErrTooLong
+	}
+	return nil
+}
+
+// normalize applies NFC and collapses whitespace. If strip is set, other
+// control characters are dropped; otherwise they are kept for validation
+// to report.
+func normalize(name string, strip bool) string {
+	name = norm.NFC.String(name)
+
+	var builder strings.Builder
+	builder.Grow(len(name))
+	space := false
+	for i := 0; i < len(name); {
+		r, size := utf8.DecodeRuneInString(name[i:])
+		switch {
+		case unicode.IsSpace(r):
+			space = true
+		case strip && isControl(r, size):
+			// Dropped without acting as a word separator.
+		default:
+			if space && builder.Len() > 0 {
+				builder.WriteByte(' ')
+			}
+			space = false
+			builder.WriteString(name[i : i+size])
+		}
+		i += size
+	}
+	return builder.String()
+}
+
+// isControl reports whether the rune r, decoded from size bytes, has no
+// place in a name: C0/C1 controls, bidirectional overrides that can
+// disguise text, and bytes that are not valid UTF-8.
+func isControl(r rune, size int) bool {
+	if r == utf8.RuneError && size == 1 {
+		return true
+	}
+	switch {
+	case unicode.IsControl(r):
+		return true
+	case r == '\u200e' || r == '\u200f' || r == '\u061c': // directional marks
+		return true
+	case r >= '\u202a' && r <= '\u202e', r >= '\u2066' && r <= '\u2069': // embeddings, overrides, isolates
+		return true
+	}
+	return false
+}
+
+// graphemeLen approximates the number of grapheme clusters in s. It joins
+// combining marks, zero-width joiner sequences, emoji modifiers, tag
+// sequences, regional-indicator flag pairs and Hangul jamo to the preceding
+// character, which covers the extended grapheme cluster rules that matter
+// for names.
+func graphemeLen(s string) int {
+	n := 0
+	var prev rune
+	joined := false // previous rune was a ZWJ
+	regional := 0   // regional indicators in the current run
+	for _, r := range s {
+		extend := false
+		switch {
+		case n == 0:
+		case joined:
+			extend = true
+		case unicode.Is(unicode.M, r):
+			extend = true
+		case r == '\u200d': // zero-width joiner
+			extend = true
+		case r >= 0x1f3fb && r <= 0x1f3ff: // emoji skin tone modifiers
+			extend = true
+		case r >= 0xe0020 && r <= 0xe007f: // emoji tag sequences
+			extend = true
+		case r >= 0x1160 && r <= 0x11ff && prev >= 0x1100 && prev <= 0x11ff: // jamo V/T
+			extend = true
+		case isRegionalIndicator(r) && regional%2 == 1:
+			extend = true
+		}
+
+		if isRegionalIndicator(r) {
+			regional++
+		} else {
+			regional = 0
+		}
+		joined = r == '\u200d'
+		prev = r
+		if !extend {
+			n++
+		}
+	}
+	return n
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
@@ -0,0 +1,104 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestNormalizeName tests NFC, whitespace collapsing and control stripping
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"unchanged", "Alice", "Alice"},
+		{"trim", "  Alice  ", "Alice"},
+		{"collapse spaces", "  John  Doe  ", "John Doe"},
+		{"newline", "John\nDoe", "John Doe"},
+		{"tab", "John\tDoe", "John Doe"},
+		{"unicode spaces", "John\u00a0\u3000Doe", "John Doe"},
+		{"null bytes", "John\x00Doe", "JohnDoe"},
+		{"bidi override", "\u202eAlice", "Alice"},
+		{"invalid utf-8", "Al\xffice", "Alice"},
+		{"nfc", "Jose\u0301", "Jos\u00e9"},
+		{"only spaces", "   ", ""},
+		{"zwj emoji kept", "👩\u200d💻", "👩\u200d💻"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := NormalizeName(tt.input); result != tt.expected {
+				t.Errorf("NormalizeName(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestValidateName tests the typed validation errors
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected error
+	}{
+		{"valid", "Alice", nil},
+		{"valid unicode", "José Müller 张三 😀", nil},
+		{"empty", "", ErrEmptyName},
+		{"blank", "   ", ErrEmptyName},
+		{"newline", "John\nDoe", ErrControlChars},
+		{"null byte", "John\x00Doe", ErrControlChars},
+		{"bidi override", "\u202eAlice", ErrControlChars},
+		{"invalid utf-8", "Al\xffice", ErrControlChars},
+		{"max length", strings.Repeat("a", MaxNameLength), nil},
+		{"too long", strings.Repeat("a", MaxNameLength+1), ErrTooLong},
+		{"combining marks count once", strings.Repeat("e\u0301\u0302", MaxNameLength), nil},
+		{"flags count once", strings.Repeat("🇵🇹", MaxNameLength), nil},
+		{"flags too long", strings.Repeat("🇵🇹", MaxNameLength+1), ErrTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateName(tt.input)
+			if !errors.Is(err, tt.expected) || (err == nil) != (tt.expected == nil) {
+				t.Errorf("ValidateName(%q) = %v, want %v", tt.input, err, tt.expected)
+			}
+		})
+	}
+}
+
+// TestSayHiStrict tests normalization and rejection in the strict variant
+func TestSayHiStrict(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		err      error
+	}{
+		{"simple name", "Alice", "Hi, Alice", nil},
+		{"trimmed", "  Alice\n", "Hi, Alice", nil},
+		{"collapsed", "Mary \t Jane", "Hi, Mary Jane", nil},
+		{"nfc", "Jose\u0301", "Hi, Jos\u00e9", nil},
+		{"empty", "", "", ErrEmptyName},
+		{"null bytes", "John\x00Doe", "", ErrControlChars},
+		{"too long", strings.Repeat("x", MaxNameLength+1), "", ErrTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := SayHiStrict(tt.input)
+			if !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+				t.Fatalf("SayHiStrict(%q) error = %v, want %v", tt.input, err, tt.err)
+			}
+			if result != tt.expected {
+				t.Errorf("SayHiStrict(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+
+			var nameErr *NameError
+			if err != nil && (!errors.As(err, &nameErr) || nameErr.Name != tt.input) {
+				t.Errorf("error %v does not carry the original name", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Person is a structured greeting recipient. Only the fields that are set
+// are used; a Person with just a GivenName greets like SayHi.
+type Person struct {
+	GivenName     string
+	FamilyName    string
+	Title         string // explicit honorific or title, e.g. "Dr."; overrides the locale's honorific
+	PreferredName string // used instead of GivenName in informal greetings
+	Pronouns      string // e.g. "she/her"; selects the locale's honorific in formal greetings
+}
+
+// Formality selects the register of a greeting.
+type Formality int
+
+const (
+	// Informal greets by preferred or given name, like SayHi.
+	Informal Formality = iota
+	// Formal greets with the locale's formal phrasing, honorific and name
+	// order, e.g. "Good day, Dr. Müller" or "田中さん、こんにちは".
+	Formal
+)
+
+// String returns "informal" or "formal".
+func (f Formality) String() string {
+	switch f {
+	case Informal:
+		return "informal"
+	case Formal:
+		return "formal"
+	}
+	return fmt.Sprintf("Formality(%d)", int(f))
+}
+
+// Catalog keys describing how a locale addresses people formally. They are
+// only honored when defined in the language the greeting itself was
+// resolved in, so rules from DefaultLocale never leak into a language that
+// merely lacks them.
+const (
+	// MessageFormal holds the formal greeting template, e.g.
+	// "Good day, {#title}{title} {/title}{name}".
+	MessageFormal = "greeting.formal"
+
+	// MessageHonorific holds the honorific used when a Person has no Title,
+	// e.g. "さん". "honorific.he", "honorific.she" and "honorific.they"
+	// hold pronoun-specific honorifics such as "Herr" and "Frau".
+	MessageHonorific = "honorific"
+
+	// MessageNameOrder is "given-first" (the default) or "family-first".
+	MessageNameOrder = "name.order"
+
+	// MessageNameSeparator separates the parts of a full name; defaults
+	// to a space.
+	MessageNameSeparator = "name.separator"
+
+	// MessageFormalName selects the name used in formal greetings:
+	// "family" (the default; the full name when there is no honorific),
+	// "given" or "full".
+	MessageFormalName = "name.formal"
+)
+
+// pronounKeys are the pronoun sets with their own honorific catalog keys.
+var pronounKeys = []string{"he", "she", "they"}
+
+// addressing is the formal-address grammar of a locale.
+type addressing struct {
+	familyFirst bool
+	separator   string
+	formalName  string
+	honorific   string
+	honorifics  map[string]string
+}
+
+func loadAddressing(c *Catalog, tag, lang string) (addressing, error) {
+	a := addressing{separator: " ", formalName: "family"}
+
+	if order, ok := lookupLanguage(c, tag, lang, MessageNameOrder); ok {
+		switch order {
+		case "given-first":
+		case "family-first":
+			a.familyFirst = true
+		default:
+			return a, fmt.Errorf("%q message for %s: unknown name order %q", MessageNameOrder, tag, order)
+		}
+	}
+	if sep, ok := lookupLanguage(c, tag, lang, MessageNameSeparator); ok {
+		a.separator = sep
+	}
+	if name, ok := lookupLanguage(c, tag, lang, MessageFormalName); ok {
+		switch name {
+		case "family", "given", "full":
+			a.formalName = name
+		default:
+			return a, fmt.Errorf("%q message for %s: unknown formal name %q", MessageFormalName, tag, name)
+		}
+	}
+
+	a.honorific, _ = lookupLanguage(c, tag, lang, MessageHonorific)
+	for _, key := range pronounKeys {
+		if h, ok := lookupLanguage(c, tag, lang, MessageHonorific+"."+key); ok {
+			if a.honorifics == nil {
+				a.honorifics = make(map[string]string, len(pronounKeys))
+			}
+			a.honorifics[key] = h
+		}
+	}
+	return a, nil
+}
+
+// lookupLanguage is like Catalog.Lookup but ignores messages found outside
+// the primary language lang.
+func lookupLanguage(c *Catalog, tag, lang, key string) (string, bool) {
+	msg, found, ok := c.Lookup(tag, key)
+	if !ok || primaryLanguage(found) != lang {
+		return "", false
+	}
+	return msg, true
+}
+
+func primaryLanguage(tag string) string {
+	if i := strings.IndexByte(tag, '-'); i >= 0 {
+		return tag[:i]
+	}
+	return tag
+}
+
+// fullName joins the name parts in the locale's order.
+func (a *addressing) fullName(p *Person) string {
+	first, second := p.GivenName, p.FamilyName
+	if a.familyFirst {
+		first, second = second, first
+	}
+	switch {
+	case first == "":
+		return second
+	case second == "":
+		return first
+	}
+	return first + a.separator + second
+}
+
+// honorificFor returns the honorific for the person's pronouns, falling
+// back to the locale's generic honorific.
+func (a *addressing) honorificFor(pronouns string) string {
+	key := strings.ToLower(strings.TrimSpace(pronouns))
+	if i := strings.IndexByte(key, '/'); i >= 0 {
+		key = key[:i]
+	}
+	if h, ok := a.honorifics[key]; ok {
+		return h
+	}
+	return a.honorific
+}
+
+// values fills v with the name and title to greet p by.
+func (a *addressing) values(p *Person, f Formality, v *Values) {
+	if f == Informal {
+		switch {
+		case p.PreferredName != "":
+			v.Name = p.PreferredName
+		case p.GivenName != "":
+			v.Name = p.GivenName
+		default:
+			v.Name = a.fullName(p)
+		}
+		return
+	}
+
+	v.Title = p.Title
+	if v.Title == "" {
+		v.Title = a.honorificFor(p.Pronouns)
+	}
+
+	switch {
+	case a.formalName == "given" && p.GivenName != "":
+		v.Name = p.GivenName
+	case a.formalName == "family" && p.FamilyName != "" && v.Title != "":
+		v.Name = p.FamilyName
+	default:
+		v.Name = a.fullName(p)
+	}
+}
+
+// WithFormality sets the register of the LocaleGreeter's greetings. Formal
+// greeters use the catalog's "greeting.formal" template when it exists.
+func WithFormality(f Formality) Option {
+	return func(cfg *greeterConfig) {
+		cfg.formality = f
+	}
+}
+
+// Formality returns the register the LocaleGreeter greets in.
+func (g *LocaleGreeter) Formality() Formality {
+	return g.formality
+}
+
+// Greet generates a greeting for p in the greeter's language and register,
+// choosing the name, name order and honorific the locale calls for.
+//
+// Example:
+//
+//	g, _ := NewGreeter("ja", WithFormality(Formal))
+//	g.Greet(Person{GivenName: "太郎", FamilyName: "田中"}) // "田中さん、こんにちは"
+func (g *LocaleGreeter) Greet(p Person) string {
+	var v Values
+	g.addressing.values(&p, g.formality, &v)
+	t := g.prepare(&v)
+	return t.Render(&v)
+}
+
+// GreetBytes returns the greeting for p as a byte slice.
+func (g *LocaleGreeter) GreetBytes(p Person) []byte {
+	var v Values
+	g.addressing.values(&p, g.formality, &v)
+	t := g.prepare(&v)
+	return t.Append(make([]byte, 0, t.Len(&v)), &v)
+}
+
+// GreetBuffer writes the greeting for p to a bytes.Buffer.
+func (g *LocaleGreeter) GreetBuffer(p Person, buf *bytes.Buffer) {
+	var v Values
+	g.addressing.values(&p, g.formality, &v)
+	t := g.prepare(&v)
+	t.WriteBuffer(buf, &v)
+}
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"testing"
+	"time"
+)
+
+// TestGreeterGreet tests formal and informal greetings across locales
+func TestGreeterGreet(t *testing.T) {
+	muller := Person{GivenName: "Anna", FamilyName: "Müller", Title: "Dr.", PreferredName: "Anni"}
+	tanaka := Person{GivenName: "太郎", FamilyName: "田中"}
+
+	tests := []struct {
+		name      string
+		tag       string
+		formality Formality
+		person    Person
+		expected  string
+	}{
+		{"informal preferred name", "en", Informal, muller, "Hi, Anni"},
+		{"informal given name", "de", Informal, Person{GivenName: "Klaus", FamilyName: "Schmidt"}, "Hallo, Klaus"},
+		{"formal title", "en", Formal, muller, "Good day, Dr. Müller"},
+		{"formal pronoun honorific", "de", Formal, Person{GivenName: "Klaus", FamilyName: "Schmidt", Pronouns: "he/him"}, "Guten Tag, Herr Schmidt"},
+		{"formal pronoun case", "fr", Formal, Person{GivenName: "Marie", FamilyName: "Curie", Pronouns: "She/Her"}, "Bonjour, Madame Curie"},
+		{"formal no honorific", "en", Formal, Person{GivenName: "Sam", FamilyName: "Lee"}, "Good day, Sam Lee"},
+		{"formal family first suffix honorific", "ja", Formal, tanaka, "田中さん、こんにちは"},
+		{"informal japanese", "ja", Informal, tanaka, "こんにちは、太郎"},
+		{"formal full name", "ko", Formal, Person{GivenName: "민수", FamilyName: "김"}, "안녕하세요, 김민수님"},
+		{"formal given name", "tr", Formal, Person{GivenName: "Ahmet", FamilyName: "Yılmaz", Pronouns: "he/him"}, "İyi günler, Ahmet Bey"},
+		{"regional fallback", "pt-BR", Formal, Person{GivenName: "João", FamilyName: "Silva", Pronouns: "he/him"}, "Bom dia, Sr. Silva"},
+		{"unknown language uses english rules", "sv", Formal, Person{GivenName: "Sam", FamilyName: "Lee", Pronouns: "they/them"}, "Good day, Mx. Lee"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGreeter(tt.tag, WithFormality(tt.formality))
+			if err != nil {
+				t.Fatalf("NewGreeter error: %v", err)
+			}
+			if result := g.Greet(tt.person); result != tt.expected {
+				t.Errorf("Greet = %q, want %q", result, tt.expected)
+			}
+			if result := string(g.GreetBytes(tt.person)); result != tt.expected {
+				t.Errorf("GreetBytes = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestGreeterGreetRulesStayInLanguage tests that English addressing rules do
+// not leak into a language that only defines an informal greeting
+func TestGreeterGreetRulesStayInLanguage(t *testing.T) {
+	c := DefaultCatalog()
+	if err := c.Set("sv", MessageGreeting, "Hej {name}"); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := NewGreeter("sv", WithCatalog(c), WithFormality(Formal))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := g.Greet(Person{GivenName: "Sam", FamilyName: "Lee", Pronouns: "he/him"})
+	if result != "Hej Sam Lee" {
+		t.Errorf("Greet = %q, want %q", result, "Hej Sam Lee")
+	}
+}
+
+// TestGreeterGreetTimeOfDay tests formal greetings in time-of-day mode
+func TestGreeterGreetTimeOfDay(t *testing.T) {
+	clock := ClockFunc(func() time.Time { return at(8, 0) })
+
+	tests := []struct {
+		tag      string
+		person   Person
+		expected string
+	}{
+		{"en", Person{GivenName: "Anna", FamilyName: "Müller", Title: "Dr."}, "Good morning, Dr. Müller"},
+		{"ja", Person{GivenName: "太郎", FamilyName: "田中"}, "おはようございます、田中さん"},
+		{"zh", Person{GivenName: "伟", FamilyName: "王", Pronouns: "he/him"}, "早上好，王先生"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			g, err := NewGreeter(tt.tag, WithFormality(Formal), WithTimeOfDay(clock))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result := g.Greet(tt.person); result != tt.expected {
+				t.Errorf("Greet = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
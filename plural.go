@@ -0,0 +1,143 @@
+package test
+
+import "fmt"
+
+// PluralCategory is a CLDR plural category. Its name doubles as the
+// catalog key suffix of pluralized messages, e.g. "list.others.few".
+type PluralCategory int
+
+const (
+	PluralZero PluralCategory = iota
+	PluralOne
+	PluralTwo
+	PluralFew
+	PluralMany
+	PluralOther
+)
+
+// String returns the CLDR name of the category: "zero", "one", "two",
+// "few", "many" or "other".
+func (c PluralCategory) String() string {
+	switch c {
+	case PluralZero:
+		return "zero"
+	case PluralOne:
+		return "one"
+	case PluralTwo:
+		return "two"
+	case PluralFew:
+		return "few"
+	case PluralMany:
+		return "many"
+	case PluralOther:
+		return "other"
+	}
+	return fmt.Sprintf("PluralCategory(%d)", int(c))
+}
+
+// pluralRule maps a non-negative integer count to its category.
+type pluralRule func(n int) PluralCategory
+
+// pluralRules holds the CLDR cardinal rules for integers, by primary
+// language. Languages without an entry only use PluralOther, like the
+// CLDR root locale.
+var pluralRules = map[string]pluralRule{}
+
+func init() {
+	// one: n = 1
+	for _, lang := range []string{"bg", "ca", "da", "de", "el", "en", "et", "fi", "gl", "hu", "nb", "nl", "no", "sv", "tr"} {
+		pluralRules[lang] = pluralOneIfOne
+	}
+	// one: n = 1; many: n != 0 and n % 1000000 = 0
+	for _, lang := range []string{"es", "it"} {
+		pluralRules[lang] = func(n int) PluralCategory {
+			if n == 1 {
+				return PluralOne
+			}
+			return pluralMillions(n)
+		}
+	}
+	// one: n = 0, 1; many: n != 0 and n % 1000000 = 0
+	for _, lang := range []string{"fr", "pt"} {
+		pluralRules[lang] = func(n int) PluralCategory {
+			if n <= 1 {
+				return PluralOne
+			}
+			return pluralMillions(n)
+		}
+	}
+	// one: n % 10 = 1 and n % 100 != 11; few: n % 10 = 2..4 and
+	// n % 100 != 12..14; many: everything else
+	for _, lang := range []string{"ru", "uk", "be"} {
+		pluralRules[lang] = func(n int) PluralCategory {
+			switch {
+			case n%10 == 1 && n%100 != 11:
+				return PluralOne
+			case isSlavicFew(n):
+				return PluralFew
+			}
+			return PluralMany
+		}
+	}
+	// one: n = 1; few: n % 10 = 2..4 and n % 100 != 12..14; many: the rest
+	pluralRules["pl"] = func(n int) PluralCategory {
+		switch {
+		case n == 1:
+			return PluralOne
+		case isSlavicFew(n):
+			return PluralFew
+		}
+		return PluralMany
+	}
+	// one: n = 1; few: n = 2..4
+	for _, lang := range []string{"cs", "sk"} {
+		pluralRules[lang] = func(n int) PluralCategory {
+			switch {
+			case n == 1:
+				return PluralOne
+			case n >= 2 && n <= 4:
+				return PluralFew
+			}
+			return PluralOther
+		}
+	}
+}
+
+func pluralOneIfOne(n int) PluralCategory {
+	if n == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func pluralMillions(n int) PluralCategory {
+	if n != 0 && n%1000000 == 0 {
+		return PluralMany
+	}
+	return PluralOther
+}
+
+func isSlavicFew(n int) bool {
+	return n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14)
+}
+
+// CardinalPlural returns the CLDR plural category of the count n in the
+// language of tag. Negative counts are treated as their absolute value.
+//
+// Example:
+//
+//	CardinalPlural("ru", 3)  // PluralFew
+//	CardinalPlural("fr", 0)  // PluralOne
+//	CardinalPlural("ja", 1)  // PluralOther
+func CardinalPlural(tag string, n int) PluralCategory {
+	if n < 0 {
+		n = -n
+	}
+	if canon, err := CanonicalTag(tag); err == nil {
+		tag = canon
+	}
+	if rule, ok := pluralRules[primaryLanguage(tag)]; ok {
+		return rule(n)
+	}
+	return PluralOther
+}
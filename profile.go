@@ -0,0 +1,258 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrProfileNotFound is returned by ProfileStore implementations for user
+// IDs without a profile. Use errors.Is to test for it.
+var ErrProfileNotFound = errors.New("profile not found")
+
+// Profile holds how a returning user likes to be greeted: by nickname or
+// name, in which language, and how formally.
+type Profile struct {
+	UserID     string `json:"user_id"`
+	Nickname   string `json:"nickname,omitempty"` // used instead of GivenName in informal greetings
+	GivenName  string `json:"given_name,omitempty"`
+	FamilyName string `json:"family_name,omitempty"`
+	Title      string `json:"title,omitempty"`
+	Pronouns   string `json:"pronouns,omitempty"`
+	Locale     string `json:"locale,omitempty"` // BCP 47 tag; empty for the greeter's own
+	Formal     bool   `json:"formal,omitempty"`
+}
+
+// Person returns the profile as the Person it greets.
+func (p *Profile) Person() Person {
+	return Person{
+		GivenName:     p.GivenName,
+		FamilyName:    p.FamilyName,
+		Title:         p.Title,
+		PreferredName: p.Nickname,
+		Pronouns:      p.Pronouns,
+	}
+}
+
+// Validate reports whether the profile can be greeted: it needs a user ID,
+// a nickname or name (a given or family name if it is formal), names that
+// pass ValidateName, and a well-formed locale if one is set.
+func (p *Profile) Validate() error {
+	if p.UserID == "" {
+		return errors.New("profile has no user ID")
+	}
+	if err := validateName(p.UserID); err != nil {
+		return fmt.Errorf("profile %q: user ID: %w", p.UserID, err)
+	}
+	if p.Nickname == "" && p.GivenName == "" && p.FamilyName == "" {
+		return fmt.Errorf("profile %q has no name", p.UserID)
+	}
+	if p.Formal && p.GivenName == "" && p.FamilyName == "" {
+		return fmt.Errorf("profile %q is formal but has no given or family name", p.UserID)
+	}
+	for _, f := range []struct{ field, value string }{
+		{"nickname", p.Nickname},
+		{"given name", p.GivenName},
+		{"family name", p.FamilyName},
+	} {
+		if f.value == "" {
+			continue
+		}
+		if err := ValidateName(f.value); err != nil {
+			return fmt.Errorf("profile %q: %s: %w", p.UserID, f.field, err)
+		}
+	}
+	if p.Locale != "" {
+		if _, err := CanonicalTag(p.Locale); err != nil {
+			return fmt.Errorf("profile %q: %w", p.UserID, err)
+		}
+	}
+	return nil
+}
+
+// ProfileStore looks up and keeps user profiles by user ID. Get returns an
+// error wrapping ErrProfileNotFound for unknown users; Put validates the
+// profile and replaces any previous one for its user ID; Delete of an
+// unknown user is not an error.
+//
+// NewMemoryProfileStore, OpenJSONProfileStore and NewSQLiteProfileStore
+// provide in-process, file and database-backed stores.
+type ProfileStore interface {
+	Get(ctx context.Context, userID string) (Profile, error)
+	Put(ctx context.Context, p Profile) error
+	Delete(ctx context.Context, userID string) error
+}
+
+// ProfileGreeter greets users by ID according to their profiles.
+//
+// Example:
+//
+//	store := NewMemoryProfileStore()
+//	store.Put(ctx, Profile{UserID: "u42", Nickname: "Ali", Locale: "es"})
+//	g, _ := NewProfileGreeter(store, DefaultLocale)
+//	g.GreetUser(ctx, "u42") // "Hola, Ali"
+//	g.GreetUser(ctx, "bob") // "Hi, bob"
+//
+// Thread Safety:
+//   A ProfileGreeter is safe for concurrent use by multiple goroutines if
+//   its store is.
+type ProfileGreeter struct {
+	store    ProfileStore
+	opts     []Option
+	fallback *LocaleGreeter
+
+	mu       sync.RWMutex
+	greeters map[profileGreeterKey]*LocaleGreeter
+}
+
+// profileGreeterKey identifies the LocaleGreeter for a profile's
+// preferences.
+type profileGreeterKey struct {
+	tag    string
+	formal bool
+}
+
+// NewProfileGreeter returns a ProfileGreeter that looks profiles up in
+// store. Users without a profile, and profiles without a locale, are
+// greeted in tag. Greeters for the locale and formality of each profile
+// are created with opts on first use, except that the profile decides
+// the formality.
+func NewProfileGreeter(store ProfileStore, tag string, opts ...Option) (*ProfileGreeter, error) {
+	fallback, err := NewGreeter(tag, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ProfileGreeter{
+		store:    store,
+		opts:     opts,
+		fallback: fallback,
+		greeters: make(map[profileGreeterKey]*LocaleGreeter),
+	}, nil
+}
+
+// GreetUser greets the user with the given ID by the nickname, language
+// and formality in their profile. A user without a profile is greeted as
+// SayHiStrict greets userID, in the ProfileGreeter's locale. Errors from
+// the store other than ErrProfileNotFound are returned.
+func (g *ProfileGreeter) GreetUser(ctx context.Context, userID string) (string, error) {
+	p, err := g.store.Get(ctx, userID)
+	if errors.Is(err, ErrProfileNotFound) {
+		return g.fallback.SayHiStrict(userID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("profile %q: %w", userID, err)
+	}
+
+	lg, err := g.greeter(p.Locale, p.Formal)
+	if err != nil {
+		return "", fmt.Errorf("profile %q: %w", userID, err)
+	}
+	return lg.Greet(p.Person()), nil
+}
+
+// greeter returns the LocaleGreeter for a locale and formality, creating
+// it on first use.
+func (g *ProfileGreeter) greeter(locale string, formal bool) (*LocaleGreeter, error) {
+	tag := g.fallback.Tag()
+	if locale != "" {
+		var err error
+		if tag, err = CanonicalTag(locale); err != nil {
+			return nil, err
+		}
+	}
+	key := profileGreeterKey{tag: tag, formal: formal}
+
+	g.mu.RLock()
+	lg, ok := g.greeters[key]
+	g.mu.RUnlock()
+	if ok {
+		return lg, nil
+	}
+
+	f := Informal
+	if formal {
+		f = Formal
+	}
+	lg, err := NewGreeter(tag, append(g.opts[:len(g.opts):len(g.opts)], WithFormality(f))...)
+	if err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if existing, ok := g.greeters[key]; ok {
+		return existing, nil
+	}
+	g.greeters[key] = lg
+	return lg, nil
+}
+
+// MemoryProfileStore is a ProfileStore kept in memory.
+//
+// Thread Safety:
+//   A MemoryProfileStore is safe for concurrent use by multiple goroutines.
+type MemoryProfileStore struct {
+	mu       sync.RWMutex
+	profiles map[string]Profile
+}
+
+// NewMemoryProfileStore returns a MemoryProfileStore holding profiles.
+// It panics if a profile is invalid, which is meant for fixed sets of
+// profiles in tests and examples.
+func NewMemoryProfileStore(profiles ...Profile) *MemoryProfileStore {
+	s := &MemoryProfileStore{profiles: make(map[string]Profile, len(profiles))}
+	for _, p := range profiles {
+		if err := p.Validate(); err != nil {
+			panic(err)
+		}
+		s.profiles[p.UserID] = p
+	}
+	return s
+}
+
+// Get returns the profile for userID.
+func (s *MemoryProfileStore) Get(ctx context.Context, userID string) (Profile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.profiles[userID]
+	if !ok {
+		return Profile{}, ErrProfileNotFound
+	}
+	return p, nil
+}
+
+// Put validates p and stores it, replacing any profile for its user ID.
+func (s *MemoryProfileStore) Put(ctx context.Context, p Profile) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profiles[p.UserID] = p
+	return nil
+}
+
+// Delete removes the profile for userID.
+func (s *MemoryProfileStore) Delete(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.profiles, userID)
+	return nil
+}
+
+// Profiles returns all profiles, sorted by user ID.
+func (s *MemoryProfileStore) Profiles() []Profile {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return sortedProfiles(s.profiles)
+}
+
+func sortedProfiles(profiles map[string]Profile) []Profile {
+	list := make([]Profile, 0, len(profiles))
+	for _, p := range profiles {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].UserID < list[j].UserID })
+	return list
+}
@@ -0,0 +1,151 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// JSONProfileStore is a ProfileStore kept in a JSON file holding an array
+// of profiles. The file is read once, when the store is opened, and
+// rewritten on every Put and Delete by replacing it with a new file, so
+// readers never see a partial write and a failed write leaves both the
+// file and the store unchanged.
+//
+// Thread Safety:
+//   A JSONProfileStore is safe for concurrent use by multiple goroutines,
+//   but not by several stores or processes sharing the file.
+type JSONProfileStore struct {
+	path string
+
+	mu       sync.RWMutex
+	profiles map[string]Profile
+}
+
+// OpenJSONProfileStore opens the profile file at path. A missing file is
+// an empty store, created by the first Put. An error is returned if the
+// file cannot be read or decoded, a profile in it is invalid, or two
+// profiles share a user ID.
+//
+// Example:
+//
+//	store, err := OpenJSONProfileStore("profiles.json")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	g, err := NewProfileGreeter(store, DefaultLocale)
+func OpenJSONProfileStore(path string) (*JSONProfileStore, error) {
+	s := &JSONProfileStore{path: path, profiles: make(map[string]Profile)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []Profile
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, p := range list {
+		if err := p.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if _, dup := s.profiles[p.UserID]; dup {
+			return nil, fmt.Errorf("%s: duplicate profile %q", path, p.UserID)
+		}
+		s.profiles[p.UserID] = p
+	}
+	return s, nil
+}
+
+// Get returns the profile for userID.
+func (s *JSONProfileStore) Get(ctx context.Context, userID string) (Profile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.profiles[userID]
+	if !ok {
+		return Profile{}, ErrProfileNotFound
+	}
+	return p, nil
+}
+
+// Put validates p, stores it, replacing any profile for its user ID, and
+// rewrites the file.
+func (s *JSONProfileStore) Put(ctx context.Context, p Profile) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, existed := s.profiles[p.UserID]
+	s.profiles[p.UserID] = p
+	if err := s.save(); err != nil {
+		if existed {
+			s.profiles[p.UserID] = old
+		} else {
+			delete(s.profiles, p.UserID)
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete removes the profile for userID and rewrites the file.
+func (s *JSONProfileStore) Delete(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.profiles[userID]
+	if !ok {
+		return nil
+	}
+	delete(s.profiles, userID)
+	if err := s.save(); err != nil {
+		s.profiles[userID] = old
+		return err
+	}
+	return nil
+}
+
+// Profiles returns all profiles, sorted by user ID.
+func (s *JSONProfileStore) Profiles() []Profile {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return sortedProfiles(s.profiles)
+}
+
+// save writes the profiles to a temporary file next to the store's file
+// and renames it over the file.
+func (s *JSONProfileStore) save() error {
+	data, err := json.MarshalIndent(sortedProfiles(s.profiles), "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	f, err := os.CreateTemp(filepath.Dir(s.path), "."+filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, s.path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
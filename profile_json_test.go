@@ -0,0 +1,98 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestJSONProfileStore tests the ProfileStore contract and persisting profiles across opens
+func TestJSONProfileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	s, err := OpenJSONProfileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testProfileStore(t, s)
+
+	ctx := context.Background()
+	want := []Profile{
+		{UserID: "u1", Nickname: "Ali", Locale: "es"},
+		{UserID: "u2", GivenName: "Anna", FamilyName: "Müller", Formal: true},
+	}
+	for _, p := range want {
+		if err := s.Put(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reopened, err := OpenJSONProfileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := reopened.Profiles()
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("reopened store holds %+v, want %+v", got, want)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil || len(entries) != 1 {
+		t.Errorf("directory holds %v, %v; want only the profile file", entries, err)
+	}
+}
+
+// TestOpenJSONProfileStoreErrors tests rejecting unreadable or invalid profile files
+func TestOpenJSONProfileStoreErrors(t *testing.T) {
+	tests := []struct {
+		content string
+		errText string
+	}{
+		{`{"user_id": "u1"}`, "cannot unmarshal"},
+		{`[{"user_id": "u1"}]`, "no name"},
+		{`[{"user_id": "u1", "nickname": "A"}, {"user_id": "u1", "nickname": "B"}]`, "duplicate profile"},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "profiles.json")
+		if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := OpenJSONProfileStore(path); err == nil || !strings.Contains(err.Error(), tt.errText) {
+			t.Errorf("OpenJSONProfileStore(%s) = %v, want error containing %q", tt.content, err, tt.errText)
+		}
+	}
+}
+
+// TestJSONProfileStoreFailedWrite tests that a failed write leaves the store unchanged
+func TestJSONProfileStoreFailedWrite(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "profiles")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	s, err := OpenJSONProfileStore(filepath.Join(dir, "profiles.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := s.Put(ctx, Profile{UserID: "u1", Nickname: "Ali"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(ctx, Profile{UserID: "u1", Nickname: "Al"}); err == nil {
+		t.Error("Put without a directory to write to succeeded")
+	}
+	if err := s.Put(ctx, Profile{UserID: "u2", Nickname: "Bo"}); err == nil {
+		t.Error("Put without a directory to write to succeeded")
+	}
+	if err := s.Delete(ctx, "u1"); err == nil {
+		t.Error("Delete without a directory to write to succeeded")
+	}
+	if got := s.Profiles(); len(got) != 1 || got[0].Nickname != "Ali" {
+		t.Errorf("store after failed writes holds %+v", got)
+	}
+}
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ProfileTable is the table SQLiteProfileStore keeps profiles in.
+const ProfileTable = "greet_profiles"
+
+// profileTableSchema creates ProfileTable if it does not exist.
+const profileTableSchema = `CREATE TABLE IF NOT EXISTS ` + ProfileTable + ` (
+	user_id     TEXT PRIMARY KEY,
+	nickname    TEXT NOT NULL DEFAULT '',
+	given_name  TEXT NOT NULL DEFAULT '',
+	family_name TEXT NOT NULL DEFAULT '',
+	title       TEXT NOT NULL DEFAULT '',
+	pronouns    TEXT NOT NULL DEFAULT '',
+	locale      TEXT NOT NULL DEFAULT '',
+	formal      INTEGER NOT NULL DEFAULT 0
+)`
+
+const (
+	selectProfile = `SELECT nickname, given_name, family_name, title, pronouns, locale, formal FROM ` +
+		ProfileTable + ` WHERE user_id = ?`
+	upsertProfile = `INSERT OR REPLACE INTO ` + ProfileTable +
+		` (user_id, nickname, given_name, family_name, title, pronouns, locale, formal) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	deleteProfile = `DELETE FROM ` + ProfileTable + ` WHERE user_id = ?`
+)
+
+// SQLiteProfileStore is a ProfileStore kept in an SQLite database, such as
+// an embedded database file opened with any SQLite database/sql driver.
+// Profiles live in ProfileTable, which NewSQLiteProfileStore creates if
+// needed. The package itself depends on no driver; the sqlitetest module
+// tests the store against modernc.org/sqlite.
+//
+// Example:
+//
+//	import _ "modernc.org/sqlite"
+//
+//	db, err := sql.Open("sqlite", "profiles.db")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	store, err := NewSQLiteProfileStore(ctx, db)
+//
+// Thread Safety:
+//   A SQLiteProfileStore is safe for concurrent use by multiple goroutines,
+//   as the *sql.DB it uses is.
+type SQLiteProfileStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteProfileStore returns a SQLiteProfileStore using db, creating
+// ProfileTable if it does not exist.
+func NewSQLiteProfileStore(ctx context.Context, db *sql.DB) (*SQLiteProfileStore, error) {
+	if _, err := db.ExecContext(ctx, profileTableSchema); err != nil {
+		return nil, fmt.Errorf("create %s: %w", ProfileTable, err)
+	}
+	return &SQLiteProfileStore{db: db}, nil
+}
+
+// Get returns the profile for userID.
+func (s *SQLiteProfileStore) Get(ctx context.Context, userID string) (Profile, error) {
+	p := Profile{UserID: userID}
+	err := s.db.QueryRowContext(ctx, selectProfile, userID).Scan(
+		&p.Nickname, &p.GivenName, &p.FamilyName, &p.Title, &p.Pronouns, &p.Locale, &p.Formal)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Profile{}, ErrProfileNotFound
+	}
+	if err != nil {
+		return Profile{}, err
+	}
+	return p, nil
+}
+
+// Put validates p and stores it, replacing any profile for its user ID.
+func (s *SQLiteProfileStore) Put(ctx context.Context, p Profile) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(ctx, upsertProfile,
+		p.UserID, p.Nickname, p.GivenName, p.FamilyName, p.Title, p.Pronouns, p.Locale, p.Formal)
+	return err
+}
+
+// Delete removes the profile for userID.
+func (s *SQLiteProfileStore) Delete(ctx context.Context, userID string) error {
+	_, err := s.db.ExecContext(ctx, deleteProfile, userID)
+	return err
+}
@@ -0,0 +1,201 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+)
+
+// fakeSQLite is a database/sql driver that understands exactly the
+// statements SQLiteProfileStore issues, storing rows in memory the way
+// SQLite would, booleans included. Databases are named by their DSN.
+type fakeSQLite struct {
+	mu  sync.Mutex
+	dbs map[string]*fakeSQLiteDB
+}
+
+type fakeSQLiteDB struct {
+	created bool
+	down    bool
+	rows    map[string][]driver.Value
+}
+
+var fakeSQLiteDriver = &fakeSQLite{dbs: make(map[string]*fakeSQLiteDB)}
+
+func init() {
+	sql.Register("fakesqlite", fakeSQLiteDriver)
+}
+
+func (d *fakeSQLite) Open(dsn string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	db, ok := d.dbs[dsn]
+	if !ok {
+		db = &fakeSQLiteDB{rows: make(map[string][]driver.Value)}
+		d.dbs[dsn] = db
+	}
+	return &fakeSQLiteConn{d: d, db: db}, nil
+}
+
+type fakeSQLiteConn struct {
+	d  *fakeSQLite
+	db *fakeSQLiteDB
+}
+
+func (c *fakeSQLiteConn) Prepare(query string) (driver.Stmt, error) {
+	switch query {
+	case profileTableSchema, selectProfile, upsertProfile, deleteProfile:
+		return &fakeSQLiteStmt{c: c, query: query}, nil
+	}
+	return nil, fmt.Errorf("fakesqlite: unsupported statement %q", query)
+}
+
+func (c *fakeSQLiteConn) Close() error { return nil }
+
+func (c *fakeSQLiteConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("fakesqlite: transactions are not supported")
+}
+
+type fakeSQLiteStmt struct {
+	c     *fakeSQLiteConn
+	query string
+}
+
+func (s *fakeSQLiteStmt) Close() error  { return nil }
+func (s *fakeSQLiteStmt) NumInput() int { return -1 }
+
+func (s *fakeSQLiteStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.c.d.mu.Lock()
+	defer s.c.d.mu.Unlock()
+	db := s.c.db
+	switch {
+	case db.down:
+		return nil, errors.New("fakesqlite: disk I/O error")
+	case s.query == profileTableSchema:
+		db.created = true
+		return driver.RowsAffected(0), nil
+	case !db.created:
+		return nil, fmt.Errorf("fakesqlite: no such table: %s", ProfileTable)
+	case s.query == upsertProfile:
+		row := append([]driver.Value(nil), args[1:]...)
+		if row[6] == true {
+			row[6] = int64(1)
+		} else {
+			row[6] = int64(0)
+		}
+		db.rows[args[0].(string)] = row
+		return driver.RowsAffected(1), nil
+	case s.query == deleteProfile:
+		delete(db.rows, args[0].(string))
+		return driver.RowsAffected(1), nil
+	}
+	return nil, fmt.Errorf("fakesqlite: %q is not an update", s.query)
+}
+
+func (s *fakeSQLiteStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.c.d.mu.Lock()
+	defer s.c.d.mu.Unlock()
+	db := s.c.db
+	switch {
+	case db.down:
+		return nil, errors.New("fakesqlite: disk I/O error")
+	case !db.created:
+		return nil, fmt.Errorf("fakesqlite: no such table: %s", ProfileTable)
+	case s.query != selectProfile:
+		return nil, fmt.Errorf("fakesqlite: %q is not a query", s.query)
+	}
+	rows := &fakeSQLiteRows{}
+	if row, ok := db.rows[args[0].(string)]; ok {
+		rows.rows = append(rows.rows, row)
+	}
+	return rows, nil
+}
+
+type fakeSQLiteRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeSQLiteRows) Columns() []string {
+	return []string{"nickname", "given_name", "family_name", "title", "pronouns", "locale", "formal"}
+}
+
+func (r *fakeSQLiteRows) Close() error { return nil }
+
+func (r *fakeSQLiteRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// openFakeSQLite opens a fresh database for the test
+func openFakeSQLite(t *testing.T) (*sql.DB, *fakeSQLiteDB) {
+	t.Helper()
+	db, err := sql.Open("fakesqlite", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		fakeSQLiteDriver.mu.Lock()
+		defer fakeSQLiteDriver.mu.Unlock()
+		delete(fakeSQLiteDriver.dbs, t.Name())
+	})
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	fakeSQLiteDriver.mu.Lock()
+	defer fakeSQLiteDriver.mu.Unlock()
+	return db, fakeSQLiteDriver.dbs[t.Name()]
+}
+
+// TestSQLiteProfileStore tests the ProfileStore contract against a database
+func TestSQLiteProfileStore(t *testing.T) {
+	db, _ := openFakeSQLite(t)
+	s, err := NewSQLiteProfileStore(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testProfileStore(t, s)
+
+	if err := s.Put(context.Background(), Profile{UserID: "u1", GivenName: "Anna", FamilyName: "Müller", Pronouns: "she/her", Locale: "de", Formal: true}); err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewProfileGreeter(s, DefaultLocale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, err := g.GreetUser(context.Background(), "u1"); err != nil || result != "Guten Tag, Frau Müller" {
+		t.Errorf("GreetUser = %q, %v", result, err)
+	}
+}
+
+// TestSQLiteProfileStoreErrors tests reporting database failures
+func TestSQLiteProfileStoreErrors(t *testing.T) {
+	db, fake := openFakeSQLite(t)
+	ctx := context.Background()
+	s, err := NewSQLiteProfileStore(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fakeSQLiteDriver.mu.Lock()
+	fake.down = true
+	fakeSQLiteDriver.mu.Unlock()
+	if _, err := s.Get(ctx, "u1"); err == nil || errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("Get from a failing database = %v", err)
+	}
+	if err := s.Put(ctx, Profile{UserID: "u1", Nickname: "Ali"}); err == nil {
+		t.Error("Put to a failing database succeeded")
+	}
+	if _, err := NewSQLiteProfileStore(ctx, db); err == nil {
+		t.Error("NewSQLiteProfileStore with a failing database succeeded")
+	}
+}
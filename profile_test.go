@@ -0,0 +1,206 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// failingProfileStore fails every lookup with err
+type failingProfileStore struct {
+	ProfileStore
+	err error
+}
+
+func (s failingProfileStore) Get(ctx context.Context, userID string) (Profile, error) {
+	return Profile{}, s.err
+}
+
+// TestGreetUser tests greeting users by their profile's name, language and formality
+func TestGreetUser(t *testing.T) {
+	store := NewMemoryProfileStore(
+		Profile{UserID: "u1", Nickname: "Ali", GivenName: "Alice", Locale: "es"},
+		Profile{UserID: "u2", GivenName: "Anna", FamilyName: "Müller", Title: "Dr.", Locale: "de", Formal: true},
+		Profile{UserID: "u3", GivenName: "太郎", FamilyName: "田中", Locale: "ja", Formal: true},
+		Profile{UserID: "u4", GivenName: "Bob"},
+		Profile{UserID: "u5", Nickname: "Jo", GivenName: "Joanna", FamilyName: "Smith", Locale: "pt-br"},
+	)
+	g, err := NewProfileGreeter(store, DefaultLocale)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		userID   string
+		expected string
+	}{
+		{"u1", "Hola, Ali"},
+		{"u2", "Guten Tag, Dr. Müller"},
+		{"u3", "田中さん、こんにちは"},
+		{"u4", "Hi, Bob"},
+		{"u5", "Oi, Jo"},
+		{"stranger", "Hi, stranger"},
+		{"  new   user ", "Hi, new user"},
+	}
+
+	for _, tt := range tests {
+		result, err := g.GreetUser(context.Background(), tt.userID)
+		if err != nil || result != tt.expected {
+			t.Errorf("GreetUser(%q) = %q, %v, want %q", tt.userID, result, err, tt.expected)
+		}
+	}
+
+	if _, err := g.GreetUser(context.Background(), ""); !errors.Is(err, ErrEmptyName) {
+		t.Errorf("GreetUser(\"\") error = %v, want %v", err, ErrEmptyName)
+	}
+}
+
+// TestGreetUserFallbackLocale tests greeting users without a profile or locale in the greeter's language
+func TestGreetUserFallbackLocale(t *testing.T) {
+	store := NewMemoryProfileStore(Profile{UserID: "u1", Nickname: "Ali"})
+	g, err := NewProfileGreeter(store, "fr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for userID, expected := range map[string]string{"u1": "Salut, Ali", "bob": "Salut, bob"} {
+		if result, err := g.GreetUser(context.Background(), userID); err != nil || result != expected {
+			t.Errorf("GreetUser(%q) = %q, %v, want %q", userID, result, err, expected)
+		}
+	}
+
+	if _, err := NewProfileGreeter(store, "not a tag"); err == nil {
+		t.Error("NewProfileGreeter accepted a malformed tag")
+	}
+}
+
+// TestGreetUserStoreError tests that store failures are reported rather than hidden by the fallback
+func TestGreetUserStoreError(t *testing.T) {
+	errDown := errors.New("database is down")
+	g, err := NewProfileGreeter(failingProfileStore{err: errDown}, DefaultLocale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, err := g.GreetUser(context.Background(), "u1"); !errors.Is(err, errDown) || result != "" {
+		t.Errorf("GreetUser = %q, %v, want %v", result, err, errDown)
+	}
+
+	g, err = NewProfileGreeter(failingProfileStore{err: fmt.Errorf("lookup: %w", ErrProfileNotFound)}, DefaultLocale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, err := g.GreetUser(context.Background(), "u1"); err != nil || result != "Hi, u1" {
+		t.Errorf("GreetUser with wrapped ErrProfileNotFound = %q, %v", result, err)
+	}
+}
+
+// TestGreetUserConcurrent tests sharing greeters between goroutines
+func TestGreetUserConcurrent(t *testing.T) {
+	store := NewMemoryProfileStore()
+	locales := []string{"en", "de", "es", "fr", "ja"}
+	for i := 0; i < 50; i++ {
+		store.Put(context.Background(), Profile{
+			UserID:     fmt.Sprint("u", i),
+			GivenName:  "Alex",
+			FamilyName: "Kim",
+			Locale:     locales[i%len(locales)],
+			Formal:     i%2 == 0,
+		})
+	}
+	g, err := NewProfileGreeter(store, DefaultLocale)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				if _, err := g.GreetUser(context.Background(), fmt.Sprint("u", i%60)); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if n := len(g.greeters); n != 2*len(locales) {
+		t.Errorf("created %d greeters, want %d", n, 2*len(locales))
+	}
+}
+
+// TestProfileValidate tests rejecting profiles that cannot be greeted
+func TestProfileValidate(t *testing.T) {
+	tests := []struct {
+		profile Profile
+		errText string
+	}{
+		{Profile{UserID: "u1", Nickname: "Ali"}, ""},
+		{Profile{UserID: "u1", FamilyName: "Kim", Formal: true, Locale: "ko"}, ""},
+		{Profile{Nickname: "Ali"}, "no user ID"},
+		{Profile{UserID: "u\x001", Nickname: "Ali"}, "user ID"},
+		{Profile{UserID: "u1"}, "no name"},
+		{Profile{UserID: "u1", Nickname: "Ali", Formal: true}, "formal"},
+		{Profile{UserID: "u1", Nickname: "Ali\n"}, "nickname"},
+		{Profile{UserID: "u1", GivenName: strings.Repeat("a", MaxNameLength+1)}, "given name"},
+		{Profile{UserID: "u1", Nickname: "Ali", Locale: "e"}, "language tag"},
+	}
+
+	for _, tt := range tests {
+		err := tt.profile.Validate()
+		switch {
+		case tt.errText == "" && err != nil:
+			t.Errorf("Validate(%+v) = %v", tt.profile, err)
+		case tt.errText != "" && (err == nil || !strings.Contains(err.Error(), tt.errText)):
+			t.Errorf("Validate(%+v) = %v, want error containing %q", tt.profile, err, tt.errText)
+		}
+	}
+}
+
+// TestMemoryProfileStore tests storing, replacing and deleting profiles
+func TestMemoryProfileStore(t *testing.T) {
+	testProfileStore(t, NewMemoryProfileStore())
+}
+
+// testProfileStore runs the ProfileStore contract against an empty store
+func testProfileStore(t *testing.T, s ProfileStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	if _, err := s.Get(ctx, "u1"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("Get of unknown user = %v, want %v", err, ErrProfileNotFound)
+	}
+	if err := s.Put(ctx, Profile{UserID: "u1"}); err == nil {
+		t.Error("Put accepted a profile without a name")
+	}
+
+	want := Profile{UserID: "u1", Nickname: "Ali", GivenName: "Alice", FamilyName: "Smith",
+		Title: "Dr.", Pronouns: "she/her", Locale: "en-GB", Formal: true}
+	if err := s.Put(ctx, want); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := s.Get(ctx, "u1"); err != nil || p != want {
+		t.Errorf("Get = %+v, %v, want %+v", p, err, want)
+	}
+
+	want = Profile{UserID: "u1", Nickname: "Al"}
+	if err := s.Put(ctx, want); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := s.Get(ctx, "u1"); err != nil || p != want {
+		t.Errorf("Get after replacing = %+v, %v, want %+v", p, err, want)
+	}
+
+	if err := s.Delete(ctx, "u1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, "u1"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("Get after Delete = %v, want %v", err, ErrProfileNotFound)
+	}
+	if err := s.Delete(ctx, "u1"); err != nil {
+		t.Errorf("Delete of unknown user = %v", err)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build race
+
+package test
+
+// raceEnabled reports whether tests run under the race detector, which
+// makes sync.Pool drop buffers at random.
+const raceEnabled = true
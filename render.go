@@ -0,0 +1,123 @@
+package test
+
+import (
+	"io"
+	"sync"
+)
+
+// renderSizeClasses are the capacities of the buffers Render pools, one
+// pool per class. A greeting is rendered into the smallest class it fits,
+// so short greetings do not hold on to buffers sized for long ones.
+// Greetings larger than the last class get a buffer of their own, which
+// Release drops rather than pools, so one huge name does not pin a large
+// buffer for the life of the process.
+var renderSizeClasses = [...]int{64, 256, 1 << 10, 4 << 10}
+
+var renderPools [len(renderSizeClasses)]sync.Pool
+
+func init() {
+	for i := range renderPools {
+		class, size := i, renderSizeClasses[i]
+		renderPools[i].New = func() interface{} {
+			return &Rendered{buf: make([]byte, 0, size), class: class}
+		}
+	}
+}
+
+// Rendered is a greeting rendered into a pooled buffer by Render. Its
+// bytes stay valid until Release, which returns the buffer to the pool;
+// a Rendered must not be used after it is released, though releasing it
+// again does nothing.
+//
+// Thread Safety:
+//   A Rendered is not safe for concurrent use, and must be released by one
+//   goroutine.
+type Rendered struct {
+	buf      []byte
+	class    int  // index into renderSizeClasses, or -1 if unpooled
+	released bool // so a second Release cannot pool it twice
+}
+
+// Render renders the greeting for name into a pooled buffer. It does not
+// allocate in the steady state, unlike SayHiBytes; call Release when done
+// with the result so the buffer can be reused.
+//
+// Example:
+//
+//	r := test.Render(name)
+//	defer r.Release()
+//	w.Write(r.Bytes())
+//
+// Thread Safety:
+//   This function is safe for concurrent use by multiple goroutines.
+func Render(name string) *Rendered {
+	r := acquireRendered(len(greetingPrefix) + len(name))
+	r.buf = AppendGreeting(r.buf, name)
+	return r
+}
+
+// Render renders the localized greeting for name into a pooled buffer.
+// See the package-level Render.
+func (g *LocaleGreeter) Render(name string) *Rendered {
+	v := Values{Name: name}
+	t := g.prepare(&v)
+	r := acquireRendered(t.Len(&v))
+	r.buf = t.Append(r.buf, &v)
+	return r
+}
+
+// Bytes returns the rendered greeting. The slice aliases the pooled
+// buffer and must not be retained after Release.
+func (r *Rendered) Bytes() []byte {
+	return r.buf
+}
+
+// String returns a copy of the rendered greeting.
+func (r *Rendered) String() string {
+	return string(r.buf)
+}
+
+// Len returns the length of the rendered greeting in bytes.
+func (r *Rendered) Len() int {
+	return len(r.buf)
+}
+
+// WriteTo writes the rendered greeting to w in a single Write call. Any
+// error from w is returned unchanged; a short write without an error is
+// reported as io.ErrShortWrite.
+func (r *Rendered) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(r.buf)
+	if err == nil && n < len(r.buf) {
+		err = io.ErrShortWrite
+	}
+	return int64(n), err
+}
+
+// Release returns the buffer to its pool. Buffers that grew past their
+// size class, or were too large for any, are left to the garbage
+// collector instead. Releasing a Rendered again does nothing.
+func (r *Rendered) Release() {
+	if r.released {
+		return
+	}
+	r.released = true
+	if r.class < 0 || cap(r.buf) != renderSizeClasses[r.class] {
+		r.buf = nil
+		return
+	}
+	r.buf = r.buf[:0]
+	renderPools[r.class].Put(r)
+}
+
+// acquireRendered returns an empty Rendered with room for n bytes, from
+// the smallest size class that fits.
+func acquireRendered(n int) *Rendered {
+	for i, size := range renderSizeClasses {
+		if n <= size {
+			r := renderPools[i].Get().(*Rendered)
+			r.released = false
+			return r
+		}
+	}
+	return &Rendered{buf: make([]byte, 0, n), class: -1}
+}
@@ -0,0 +1,219 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestRender tests rendering into size-classed buffers
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+	}{
+		{"", 64},
+		{"Alice", 64},
+		{strings.Repeat("x", 60), 64},
+		{strings.Repeat("x", 61), 256},
+		{strings.Repeat("é", 500), 1 << 10},
+		{strings.Repeat("x", 4<<10-4), 4 << 10},
+		{strings.Repeat("x", 4<<10), 4<<10 + 4},
+	}
+
+	for _, tt := range tests {
+		r := Render(tt.name)
+		want := SayHi(tt.name)
+		if r.String() != want || string(r.Bytes()) != want || r.Len() != len(want) {
+			t.Errorf("Render(%d bytes) = %d bytes, want %d", len(tt.name), r.Len(), len(want))
+		}
+		if cap(r.Bytes()) != tt.capacity {
+			t.Errorf("Render(%d bytes) capacity = %d, want %d", len(tt.name), cap(r.Bytes()), tt.capacity)
+		}
+		r.Release()
+	}
+}
+
+// TestRenderedWriteTo tests writing rendered greetings and propagating writer errors
+func TestRenderedWriteTo(t *testing.T) {
+	r := Render("Alice")
+	defer r.Release()
+
+	var buf bytes.Buffer
+	if n, err := r.WriteTo(&buf); err != nil || n != int64(len("Hi, Alice")) || buf.String() != "Hi, Alice" {
+		t.Errorf("WriteTo = %d, %v, wrote %q", n, err, buf.String())
+	}
+
+	errBroken := errors.New("broken pipe")
+	if n, err := r.WriteTo(&failingWriter{limit: 3, err: errBroken}); !errors.Is(err, errBroken) || n != 3 {
+		t.Errorf("WriteTo failing writer = %d, %v, want 3, %v", n, err, errBroken)
+	}
+	if n, err := r.WriteTo(&failingWriter{limit: 3}); err != io.ErrShortWrite || n != 3 {
+		t.Errorf("WriteTo short writer = %d, %v, want 3, %v", n, err, io.ErrShortWrite)
+	}
+}
+
+// TestRenderedRelease tests that only buffers of their class's size are pooled
+func TestRenderedRelease(t *testing.T) {
+	tests := []struct {
+		desc   string
+		r      *Rendered
+		pooled bool
+	}{
+		{"fits its class", &Rendered{buf: make([]byte, 10, 64), class: 0}, true},
+		{"grew past its class", &Rendered{buf: make([]byte, 10, 128), class: 0}, false},
+		{"oversized", &Rendered{buf: make([]byte, 10, 8<<10), class: -1}, false},
+	}
+
+	for _, tt := range tests {
+		tt.r.Release()
+		if pooled := tt.r.buf != nil; pooled != tt.pooled {
+			t.Errorf("%s: pooled = %v, want %v", tt.desc, pooled, tt.pooled)
+		}
+		if tt.pooled && len(tt.r.buf) != 0 {
+			t.Errorf("%s: released with %d bytes", tt.desc, len(tt.r.buf))
+		}
+	}
+}
+
+// TestRenderedReleaseTwice tests that releasing twice does not pool one
+// buffer twice, which would let two later results share it
+func TestRenderedReleaseTwice(t *testing.T) {
+	r := Render("Alice")
+	r.Release()
+	r.Release()
+
+	a, b := Render("Ann"), Render("Bob")
+	defer a.Release()
+	defer b.Release()
+	if a == b {
+		t.Fatal("two Render results share one Rendered")
+	}
+	if a.String() != SayHi("Ann") || b.String() != SayHi("Bob") {
+		t.Errorf("Render results = %q and %q, want %q and %q", a.String(), b.String(), SayHi("Ann"), SayHi("Bob"))
+	}
+}
+
+// TestLocaleGreeterRender tests rendering localized greetings
+func TestLocaleGreeterRender(t *testing.T) {
+	g, err := NewGreeter("pt-BR", WithFormality(Formal))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Alice", strings.Repeat("João ", 300)} {
+		r := g.Render(name)
+		if result := r.String(); result != g.SayHi(name) {
+			t.Errorf("Render(%.20q) = %.20q, want %.20q", name, result, g.SayHi(name))
+		}
+		r.Release()
+	}
+}
+
+// TestRenderConcurrent tests that pooled buffers are never shared between live results
+func TestRenderConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				name := fmt.Sprintf("%s%d-%d", strings.Repeat("n", j%300), i, j)
+				r := Render(name)
+				runtime.Gosched()
+				if r.String() != SayHi(name) {
+					t.Errorf("Render(%q) = %q", name, r.String())
+				}
+				r.Release()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// TestRenderAllocs tests the zero-allocation guarantee
+func TestRenderAllocs(t *testing.T) {
+	g, err := NewGreeter("de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := testing.AllocsPerRun(100, func() { Render("Alice").Release() }); n != 0 {
+		t.Errorf("Render allocs = %v, want 0", n)
+	}
+	if n := testing.AllocsPerRun(100, func() { g.Render("Alice").Release() }); n != 0 {
+		t.Errorf("LocaleGreeter.Render allocs = %v, want 0", n)
+	}
+}
+
+// renderBenchmarkNames are the name sizes the GC comparison runs with: one
+// per size class, and one too large to pool
+var renderBenchmarkNames = []struct {
+	size string
+	name string
+}{
+	{"short", "Benchmark"},
+	{"medium", strings.Repeat("x", 200)},
+	{"long", strings.Repeat("x", 3000)},
+	{"oversized", strings.Repeat("x", 16<<10)},
+}
+
+// BenchmarkRender benchmarks pooled rendering against SayHiBytes. Run it
+// across GOMAXPROCS values and with the race detector to compare
+// allocations and collections under contention:
+//
+//	go test -run='^$' -bench=Render -benchmem -cpu 1,2,4,8 .
+//	go test -race -run='^$' -bench=Render -benchmem -cpu 1,8 .
+//
+// gc/Mop is the number of garbage collections per million greetings.
+func BenchmarkRender(b *testing.B) {
+	for _, n := range renderBenchmarkNames {
+		name := n.name
+		b.Run("SayHiBytes/"+n.size, func(b *testing.B) {
+			benchmarkGC(b, func() {
+				io.Discard.Write(SayHiBytes(name))
+			})
+		})
+		b.Run("Render/"+n.size, func(b *testing.B) {
+			benchmarkGC(b, func() {
+				r := Render(name)
+				io.Discard.Write(r.Bytes())
+				r.Release()
+			})
+		})
+	}
+}
+
+// BenchmarkLocaleGreeterRender benchmarks pooled localized rendering
+func BenchmarkLocaleGreeterRender(b *testing.B) {
+	g, err := NewGreeter("de", WithFormality(Formal))
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkGC(b, func() {
+		r := g.Render("Benchmark")
+		io.Discard.Write(r.Bytes())
+		r.Release()
+	})
+}
+
+// benchmarkGC runs greet in parallel and reports collections per million
+// calls alongside allocations
+func benchmarkGC(b *testing.B, greet func()) {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			greet()
+		}
+	})
+	b.StopTimer()
+	runtime.ReadMemStats(&after)
+	b.ReportMetric(float64(after.NumGC-before.NumGC)*1e6/float64(b.N), "gc/Mop")
+}
@@ -1,15 +1,25 @@
 // Package test provides simple greeting functionality for applications.
-// 
+//
 // This package contains utilities for generating personalized greeting messages.
 // It is designed to be lightweight and easy to integrate into any Go application.
+//
+// SayHi has two implementations: the optimized one in say_optimized.go,
+// used by default, and SayHiReference, a direct fmt.Sprintf kept as the
+// specification the optimized one is tested against. Building with
+//
+//	go build -tags greet_reference
+//
+// makes SayHi use the reference implementation, e.g. to rule out the
+// optimized code when chasing a bug.
 package test
 
-import "fmt" 
+import "fmt"
 
 // SayHi generates a personalized greeting message for the given name.
 //
 // The function takes a name parameter and returns a formatted greeting string.
 // If an empty string is provided, it will still generate a valid greeting.
+// SayHi always greets in DefaultLocale; use NewGreeter for other languages.
 //
 // Example:
 //
@@ -25,5 +35,13 @@ import "fmt"
 // Thread Safety:
 //   This function is safe for concurrent use by multiple goroutines.
 func SayHi(name string) string {
-   return fmt.Sprintf("Hi, %s", name)
+	return sayHi(name)
+}
+
+// SayHiReference is the reference implementation of SayHi: slower, but
+// obviously correct. SayHi, SayHiBytes and SayHiBuffer must produce
+// exactly what it does for every input, which the package's differential
+// tests check.
+func SayHiReference(name string) string {
+	return fmt.Sprintf("Hi, %s", name)
 }
@@ -0,0 +1,116 @@
+package test
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestSayHi tests greetings for plain, Unicode and special-character names
+func TestSayHi(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Alice", "Hi, Alice"},
+		{"", "Hi, "},
+		{"A", "Hi, A"},
+		{"John123", "Hi, John123"},
+		{"José", "Hi, José"},
+		{"Иван", "Hi, Иван"},
+		{"张三", "Hi, 张三"},
+		{"😀", "Hi, 😀"},
+		{"O'Connor", "Hi, O'Connor"},
+		{"  John  Doe  ", "Hi,   John  Doe  "},
+		{`"John"`, `Hi, "John"`},
+		{"John\\Doe", "Hi, John\\Doe"},
+		{"%s %d %!", "Hi, %s %d %!"},
+		{"\xff", "Hi, \xff"},
+	}
+
+	for _, tt := range tests {
+		if result := SayHi(tt.input); result != tt.expected {
+			t.Errorf("SayHi(%q) = %q, want %q", tt.input, result, tt.expected)
+		}
+		if result := string(SayHiBytes(tt.input)); result != tt.expected {
+			t.Errorf("SayHiBytes(%q) = %q, want %q", tt.input, result, tt.expected)
+		}
+		var buf bytes.Buffer
+		buf.WriteString("> ")
+		SayHiBuffer(tt.input, &buf)
+		if result := buf.String(); result != "> "+tt.expected {
+			t.Errorf("SayHiBuffer(%q) wrote %q, want it appended", tt.input, result)
+		}
+	}
+}
+
+// FuzzSayHi tests the invariants shared by the SayHi family; its seed
+// corpus is in testdata/fuzz/FuzzSayHi
+func FuzzSayHi(f *testing.F) {
+	const prefix = "Hi, "
+	en, err := NewGreeter("en")
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, name string) {
+		s := SayHi(name)
+		if ref := SayHiReference(name); s != ref {
+			t.Errorf("SayHi(%q) = %q, reference %q (SayHi is %s)", name, s, ref, sayHiImplementation)
+		}
+		if opt := sayHiOptimized(name); opt != s {
+			t.Errorf("optimized SayHi(%q) = %q, want %q", name, opt, s)
+		}
+		if b := SayHiBytes(name); string(b) != s {
+			t.Errorf("SayHiBytes(%q) = %q, SayHi = %q", name, b, s)
+		}
+		var buf bytes.Buffer
+		SayHiBuffer(name, &buf)
+		if buf.String() != s {
+			t.Errorf("SayHiBuffer(%q) = %q, SayHi = %q", name, buf.String(), s)
+		}
+		if a := AppendGreeting(nil, name); string(a) != s {
+			t.Errorf("AppendGreeting(%q) = %q, SayHi = %q", name, a, s)
+		}
+		if g := en.SayHi(name); g != s {
+			t.Errorf("English LocaleGreeter.SayHi(%q) = %q, SayHi = %q", name, g, s)
+		}
+
+		if !strings.HasPrefix(s, prefix) || s[len(prefix):] != name {
+			t.Errorf("SayHi(%q) = %q, want the prefix and the name unchanged", name, s)
+		}
+		if len(s) != len(prefix)+len(name) {
+			t.Errorf("len(SayHi(%q)) = %d, want %d", name, len(s), len(prefix)+len(name))
+		}
+		if utf8.ValidString(name) && !utf8.ValidString(s) {
+			t.Errorf("SayHi(%q) = %q is invalid UTF-8", name, s)
+		}
+	})
+}
+
+// TestSayHiDifferential tests that every implementation matches the
+// reference on generated names, whether or not fuzzing is enabled
+func TestSayHiDifferential(t *testing.T) {
+	// Bytes from every range UTF-8 treats differently, so names include
+	// ASCII, multi-byte runes, and invalid and truncated sequences.
+	alphabet := []string{"a", "Z", " ", "%", "{", "\x00", "\t", "é", "张", "😀", "\u200d", "\xff", "\xc3", "\xe5\xbc"}
+	rng := rand.New(rand.NewSource(1))
+	var buf bytes.Buffer
+	for i := 0; i < 5000; i++ {
+		var b strings.Builder
+		for n := rng.Intn(40); n > 0; n-- {
+			b.WriteString(alphabet[rng.Intn(len(alphabet))])
+		}
+		name := b.String()
+
+		want := SayHiReference(name)
+		buf.Reset()
+		SayHiBuffer(name, &buf)
+		if SayHi(name) != want || sayHiOptimized(name) != want || string(SayHiBytes(name)) != want || buf.String() != want {
+			t.Fatalf("implementations disagree on %q: reference %q, SayHi (%s) %q, optimized %q, bytes %q, buffer %q",
+				name, want, sayHiImplementation, SayHi(name), sayHiOptimized(name), SayHiBytes(name), buf.String())
+		}
+	}
+}
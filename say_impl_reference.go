@@ -0,0 +1,10 @@
+//go:build greet_reference
+
+package test
+
+// sayHiImplementation names the implementation behind SayHi.
+const sayHiImplementation = "reference"
+
+func sayHi(name string) string {
+	return SayHiReference(name)
+}
@@ -32,4 +32,11 @@ func BenchmarkSayHiUnicode(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		SayHi(unicodeName)
 	}
-}
\ No newline at end of file
+}
+
+// BenchmarkSayHiReference benchmarks the fmt.Sprintf reference implementation
+func BenchmarkSayHiReference(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		SayHiReference("Benchmark")
+	}
+}
@@ -0,0 +1,6 @@
+// Package sqlitetest runs SQLiteProfileStore against a real SQLite engine,
+// modernc.org/sqlite. It is a module of its own so that the greet module
+// depends on no SQLite driver; run its tests with
+//
+//	cd sqlitetest && go test ./...
+package sqlitetest
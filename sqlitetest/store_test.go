@@ -0,0 +1,114 @@
+package sqlitetest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	greet "github.com/zhangbaodong/test"
+	_ "modernc.org/sqlite"
+)
+
+// openStore opens a SQLiteProfileStore on a new database file
+func openStore(t *testing.T) (*greet.SQLiteProfileStore, *sql.DB, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "profiles.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	s, err := greet.NewSQLiteProfileStore(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, db, path
+}
+
+// TestSQLiteProfileStore tests storing, replacing and deleting profiles
+func TestSQLiteProfileStore(t *testing.T) {
+	s, _, _ := openStore(t)
+	ctx := context.Background()
+
+	if _, err := s.Get(ctx, "u1"); !errors.Is(err, greet.ErrProfileNotFound) {
+		t.Errorf("Get of unknown user = %v, want %v", err, greet.ErrProfileNotFound)
+	}
+	if err := s.Put(ctx, greet.Profile{UserID: "u1"}); err == nil {
+		t.Error("Put accepted a profile without a name")
+	}
+
+	tests := []greet.Profile{
+		{UserID: "u1", Nickname: "Ali", GivenName: "Alice", FamilyName: "Smith",
+			Title: "Dr.", Pronouns: "she/her", Locale: "en-GB", Formal: true},
+		{UserID: "u1", Nickname: "Al"},
+		{UserID: "u1", GivenName: "太郎", FamilyName: "田中", Locale: "ja", Formal: true},
+	}
+	for _, want := range tests {
+		if err := s.Put(ctx, want); err != nil {
+			t.Fatal(err)
+		}
+		if p, err := s.Get(ctx, want.UserID); err != nil || p != want {
+			t.Errorf("Get = %+v, %v, want %+v", p, err, want)
+		}
+	}
+
+	if err := s.Delete(ctx, "u1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, "u1"); !errors.Is(err, greet.ErrProfileNotFound) {
+		t.Errorf("Get after Delete = %v, want %v", err, greet.ErrProfileNotFound)
+	}
+	if err := s.Delete(ctx, "u1"); err != nil {
+		t.Errorf("Delete of unknown user = %v", err)
+	}
+}
+
+// TestSQLiteProfileStoreReopen tests that profiles outlive the connection
+// and that opening an existing database keeps its table
+func TestSQLiteProfileStoreReopen(t *testing.T) {
+	s, db, path := openStore(t)
+	ctx := context.Background()
+	want := greet.Profile{UserID: "u1", GivenName: "Anna", FamilyName: "Müller", Pronouns: "she/her", Locale: "de", Formal: true}
+	if err := s.Put(ctx, want); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	s, err = greet.NewSQLiteProfileStore(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err := s.Get(ctx, "u1"); err != nil || p != want {
+		t.Errorf("Get after reopening = %+v, %v, want %+v", p, err, want)
+	}
+
+	g, err := greet.NewProfileGreeter(s, greet.DefaultLocale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, err := g.GreetUser(ctx, "u1"); err != nil || result != "Guten Tag, Frau Müller" {
+		t.Errorf("GreetUser = %q, %v", result, err)
+	}
+}
+
+// TestSQLiteProfileStoreFormal tests reading formality written by other programs
+func TestSQLiteProfileStoreFormal(t *testing.T) {
+	s, db, _ := openStore(t)
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, `INSERT INTO `+greet.ProfileTable+
+		` (user_id, family_name, formal) VALUES ('u1', 'Kim', 1), ('u2', 'Lee', 0)`); err != nil {
+		t.Fatal(err)
+	}
+	for userID, formal := range map[string]bool{"u1": true, "u2": false} {
+		if p, err := s.Get(ctx, userID); err != nil || p.Formal != formal {
+			t.Errorf("Get(%q) = %+v, %v, want Formal %v", userID, p, err, formal)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Greeter is a greeting strategy: it greets a name, or rejects it with an
+// error such as a *NameError. *LocaleGreeter, GreetFunc and the decorators
+// returned by Chain implement it, so callers can swap how greetings are
+// made, per tenant or in tests, without changing code that uses them.
+type Greeter interface {
+	SayHiStrict(name string) (string, error)
+}
+
+var _ Greeter = (*LocaleGreeter)(nil)
+
+// SayHiStrict calls f(name), making any GreetFunc a Greeter.
+func (f GreetFunc) SayHiStrict(name string) (string, error) {
+	return f(name)
+}
+
+// ErrUnknownStrategy is returned by NewStrategy for names that were never
+// registered.
+var ErrUnknownStrategy = errors.New("unknown greeting strategy")
+
+// Built-in strategy names.
+const (
+	// StrategyClassic greets like SayHiStrict, in English whatever the
+	// tag.
+	StrategyClassic = "classic"
+	// StrategyLocalized greets like NewGreeter.
+	StrategyLocalized = "localized"
+	// StrategyFormal greets like NewGreeter with WithFormality(Formal).
+	StrategyFormal = "formal"
+	// StrategyTimeOfDay greets like NewGreeter with WithTimeOfDay(nil),
+	// unless the options set a clock of their own.
+	StrategyTimeOfDay = "time-of-day"
+	// StrategyTemplate greets like NewGreeter with the template the
+	// options must set with WithTemplate.
+	StrategyTemplate = "template"
+)
+
+// StrategyFunc builds a Greeter for a BCP 47 language tag.
+type StrategyFunc func(tag string, opts ...Option) (Greeter, error)
+
+var (
+	strategiesMu sync.RWMutex
+	strategies   = map[string]StrategyFunc{
+		StrategyClassic: func(tag string, opts ...Option) (Greeter, error) {
+			return GreetFunc(SayHiStrict), nil
+		},
+		StrategyLocalized: func(tag string, opts ...Option) (Greeter, error) {
+			return NewGreeter(tag, opts...)
+		},
+		StrategyFormal: func(tag string, opts ...Option) (Greeter, error) {
+			return NewGreeter(tag, append(opts[:len(opts):len(opts)], WithFormality(Formal))...)
+		},
+		StrategyTimeOfDay: func(tag string, opts ...Option) (Greeter, error) {
+			if cfg := applyOptions(opts); cfg.clock == nil {
+				opts = append(opts[:len(opts):len(opts)], WithTimeOfDay(nil))
+			}
+			return NewGreeter(tag, opts...)
+		},
+		StrategyTemplate: func(tag string, opts ...Option) (Greeter, error) {
+			if cfg := applyOptions(opts); cfg.template == nil {
+				return nil, errors.New("template strategy needs WithTemplate")
+			}
+			return NewGreeter(tag, opts...)
+		},
+	}
+)
+
+// applyOptions returns the configuration opts describe.
+func applyOptions(opts []Option) greeterConfig {
+	var cfg greeterConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// RegisterStrategy makes a strategy available to NewStrategy under name,
+// typically from an init function. It panics if name is already registered
+// or f is nil.
+//
+// Example:
+//
+//	func init() {
+//		test.RegisterStrategy("pirate", func(tag string, opts ...test.Option) (test.Greeter, error) {
+//			return test.NewGreeter(tag, append(opts, test.WithTemplate(test.MustParseTemplate("Ahoy, {name}!")))...)
+//		})
+//	}
+func RegisterStrategy(name string, f StrategyFunc) {
+	if f == nil {
+		panic("test: RegisterStrategy with nil func for " + name)
+	}
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+	if _, dup := strategies[name]; dup {
+		panic("test: RegisterStrategy called twice for " + name)
+	}
+	strategies[name] = f
+}
+
+// NewStrategy returns a Greeter for tag built by the strategy registered
+// under name: one of the Strategy constants or a name passed to
+// RegisterStrategy. Unknown names return an error wrapping
+// ErrUnknownStrategy.
+//
+// Example:
+//
+//	g, err := NewStrategy(StrategyFormal, "de")
+//	greeting, err := g.SayHiStrict("Anna") // "Guten Tag, Anna"
+//
+// Thread Safety:
+//   NewStrategy, RegisterStrategy and Strategies are safe for concurrent
+//   use by multiple goroutines.
+func NewStrategy(name, tag string, opts ...Option) (Greeter, error) {
+	strategiesMu.RLock()
+	f, ok := strategies[name]
+	strategiesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownStrategy, name)
+	}
+	return f(tag, opts...)
+}
+
+// Strategies returns the sorted names of the registered strategies.
+func Strategies() []string {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,133 @@
+package test
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestNewStrategy tests the built-in strategies
+func TestNewStrategy(t *testing.T) {
+	evening := WithTimeOfDay(ClockFunc(func() time.Time {
+		return time.Date(2024, 5, 1, 19, 0, 0, 0, time.UTC)
+	}))
+	tests := []struct {
+		strategy string
+		tag      string
+		opts     []Option
+		expected string
+	}{
+		{StrategyClassic, "de", nil, "Hi, Anna"},
+		{StrategyLocalized, "de", nil, "Hallo, Anna"},
+		{StrategyFormal, "de", nil, "Guten Tag, Anna"},
+		{StrategyTimeOfDay, "en", []Option{evening}, "Good evening, Anna"},
+		{StrategyTemplate, "en", []Option{WithTemplate(MustParseTemplate("Yo {name}"))}, "Yo Anna"},
+	}
+
+	for _, tt := range tests {
+		g, err := NewStrategy(tt.strategy, tt.tag, tt.opts...)
+		if err != nil {
+			t.Errorf("NewStrategy(%q, %q): %v", tt.strategy, tt.tag, err)
+			continue
+		}
+		result, err := g.SayHiStrict(" Anna ")
+		if err != nil || result != tt.expected {
+			t.Errorf("%s: SayHiStrict = %q, %v, want %q", tt.strategy, result, err, tt.expected)
+		}
+		if _, err := g.SayHiStrict(""); !errors.Is(err, ErrEmptyName) {
+			t.Errorf("%s: empty name gave %v", tt.strategy, err)
+		}
+	}
+
+	if _, err := NewStrategy("shouting", "en"); !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("unknown strategy: %v", err)
+	}
+	if _, err := NewStrategy(StrategyTemplate, "en"); err == nil {
+		t.Error("template strategy without a template succeeded")
+	}
+	if _, err := NewStrategy(StrategyLocalized, "not a tag"); err == nil {
+		t.Error("bad tag succeeded")
+	}
+}
+
+// TestNewStrategyOptions tests that strategies adding options leave the
+// caller's slice alone, so goroutines may share one
+func TestNewStrategyOptions(t *testing.T) {
+	for _, name := range []string{StrategyFormal, StrategyTimeOfDay} {
+		opts := make([]Option, 1, 2)
+		opts[0] = WithFormality(Informal)
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := NewStrategy(name, "en", opts...); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+		if opts[:2][1] != nil {
+			t.Errorf("%s wrote into the spare capacity of its options", name)
+		}
+	}
+}
+
+// unregisterStrategy removes a strategy registered by a test
+func unregisterStrategy(name string) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+	delete(strategies, name)
+}
+
+// TestRegisterStrategy tests adding strategies to the registry
+func TestRegisterStrategy(t *testing.T) {
+	t.Cleanup(func() { unregisterStrategy("test-upper") })
+	RegisterStrategy("test-upper", func(tag string, opts ...Option) (Greeter, error) {
+		g, err := NewGreeter(tag, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return GreetFunc(func(name string) (string, error) {
+			greeting, err := g.SayHiStrict(name)
+			return strings.ToUpper(greeting), err
+		}), nil
+	})
+
+	g, err := NewStrategy("test-upper", "fr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := g.SayHiStrict("Anna"); result != "SALUT, ANNA" {
+		t.Errorf("SayHiStrict = %q", result)
+	}
+
+	names := Strategies()
+	if !sort.StringsAreSorted(names) || !containsString(names, "test-upper") || !containsString(names, StrategyClassic) {
+		t.Errorf("Strategies() = %v", names)
+	}
+
+	for _, name := range []string{"test-upper", StrategyClassic} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("registering %q twice did not panic", name)
+				}
+			}()
+			RegisterStrategy(name, func(string, ...Option) (Greeter, error) { return nil, nil })
+		}()
+	}
+}
+
+// containsString reports whether list holds s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
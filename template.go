@@ -0,0 +1,290 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Values holds the data a Template is rendered with. Empty fields render as
+// empty strings and make conditional sections on them disappear.
+type Values struct {
+	Name      string // {name}
+	Title     string // {title}, e.g. "Dr."
+	TimeOfDay string // {timeofday}, e.g. "Good morning"
+	Punct     string // {punct}, the locale's greeting separator, e.g. ", " or "、"
+}
+
+// field identifies a placeholder in a template.
+type field uint8
+
+const (
+	fieldName field = iota
+	fieldTitle
+	fieldTimeOfDay
+	fieldPunct
+)
+
+var fieldNames = map[string]field{
+	"name":      fieldName,
+	"title":     fieldTitle,
+	"timeofday": fieldTimeOfDay,
+	"punct":     fieldPunct,
+}
+
+func (v *Values) get(f field) string {
+	switch f {
+	case fieldName:
+		return v.Name
+	case fieldTitle:
+		return v.Title
+	case fieldTimeOfDay:
+		return v.TimeOfDay
+	default:
+		return v.Punct
+	}
+}
+
+type nodeKind uint8
+
+const (
+	nodeText     nodeKind = iota // literal text
+	nodeField                    // {field}
+	nodeIf                       // {#field} ... {/field}
+	nodeIfNot                    // {^field} ... {/field}
+	nodeEndBlock                 // {/field}
+)
+
+// node is one instruction of a compiled template. Sections are flattened:
+// an opening node stores in skip the index just past its closing node.
+type node struct {
+	kind  nodeKind
+	field field
+	text  string
+	skip  int
+}
+
+// Template is a compiled greeting format. Parse it once with ParseTemplate
+// and render it many times; rendering does not allocate beyond the output.
+//
+// Syntax:
+//   - {name}, {title}, {timeofday}, {punct} insert the matching Values field
+//   - {#title}...{/title} renders its body only if Title is not empty
+//   - {^title}...{/title} renders its body only if Title is empty
+//   - {{ and }} produce literal braces
+//
+// Example:
+//
+//	t := MustParseTemplate("{timeofday}{punct}{#title}{title} {/title}{name}!")
+//	t.Render(&Values{Name: "Müller", Title: "Dr.", TimeOfDay: "Good day", Punct: ", "})
+//	// Output: Good day, Dr. Müller!
+//
+// Thread Safety:
+//   A Template is immutable and safe for concurrent use by multiple goroutines.
+type Template struct {
+	src   string
+	nodes []node
+}
+
+// ParseError describes a problem with a template source, including where
+// in the source it was found.
+type ParseError struct {
+	Template string // the template source
+	Offset   int    // byte offset of the problem
+	Line     int    // 1-based line number
+	Column   int    // 1-based column, counted in runes
+	Msg      string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("template:%d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
+func newParseError(src string, offset int, format string, args ...interface{}) *ParseError {
+	before := src[:offset]
+	line := strings.Count(before, "\n") + 1
+	if i := strings.LastIndexByte(before, '\n'); i >= 0 {
+		before = before[i+1:]
+	}
+	return &ParseError{
+		Template: src,
+		Offset:   offset,
+		Line:     line,
+		Column:   utf8.RuneCountInString(before) + 1,
+		Msg:      fmt.Sprintf(format, args...),
+	}
+}
+
+// ParseTemplate compiles a greeting template. Syntax errors are reported as
+// a *ParseError.
+func ParseTemplate(src string) (*Template, error) {
+	t := &Template{src: src}
+
+	type open struct {
+		index  int
+		offset int
+		tag    string
+	}
+	var stack []open
+	var text strings.Builder
+
+	flush := func() {
+		if text.Len() > 0 {
+			t.nodes = append(t.nodes, node{kind: nodeText, text: text.String()})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '{' && strings.HasPrefix(src[i:], "{{"):
+			text.WriteByte('{')
+			i += 2
+			continue
+		case c == '}' && strings.HasPrefix(src[i:], "}}"):
+			text.WriteByte('}')
+			i += 2
+			continue
+		case c == '}':
+			return nil, newParseError(src, i, "unexpected %q; use %q for a literal brace", "}", "}}")
+		case c != '{':
+			text.WriteByte(c)
+			i++
+			continue
+		}
+
+		end := strings.IndexByte(src[i:], '}')
+		if end < 0 {
+			return nil, newParseError(src, i, "unclosed placeholder")
+		}
+		tag := src[i+1 : i+end]
+
+		kind := nodeField
+		name := tag
+		if tag != "" {
+			switch tag[0] {
+			case '#':
+				kind, name = nodeIf, tag[1:]
+			case '^':
+				kind, name = nodeIfNot, tag[1:]
+			case '/':
+				kind, name = nodeEndBlock, tag[1:]
+			}
+		}
+
+		f, ok := fieldNames[name]
+		if !ok {
+			if name == "" {
+				return nil, newParseError(src, i, "empty placeholder")
+			}
+			return nil, newParseError(src, i, "unknown placeholder %q", name)
+		}
+
+		flush()
+		switch kind {
+		case nodeIf, nodeIfNot:
+			stack = append(stack, open{index: len(t.nodes), offset: i, tag: tag})
+		case nodeEndBlock:
+			if len(stack) == 0 {
+				return nil, newParseError(src, i, "{/%s} without matching section", name)
+			}
+			top := stack[len(stack)-1]
+			if t.nodes[top.index].field != f {
+				return nil, newParseError(src, i, "{/%s} closes section {%s}", name, top.tag)
+			}
+			stack = stack[:len(stack)-1]
+			t.nodes[top.index].skip = len(t.nodes) + 1
+		}
+		t.nodes = append(t.nodes, node{kind: kind, field: f})
+		i += end + 1
+	}
+
+	if len(stack) > 0 {
+		top := stack[len(stack)-1]
+		return nil, newParseError(src, top.offset, "section {%s} is never closed", top.tag)
+	}
+	flush()
+	return t, nil
+}
+
+// MustParseTemplate is like ParseTemplate but panics if the template cannot
+// be parsed. It simplifies initialization of package-level templates.
+func MustParseTemplate(src string) *Template {
+	t, err := ParseTemplate(src)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// String returns the template source.
+func (t *Template) String() string {
+	return t.src
+}
+
+// Len returns the length in bytes of the template rendered with v.
+func (t *Template) Len(v *Values) int {
+	n := 0
+	for i := 0; i < len(t.nodes); {
+		var s string
+		s, i = t.step(i, v)
+		n += len(s)
+	}
+	return n
+}
+
+// Append appends the template rendered with v to dst and returns the
+// extended buffer.
+func (t *Template) Append(dst []byte, v *Values) []byte {
+	for i := 0; i < len(t.nodes); {
+		var s string
+		s, i = t.step(i, v)
+		dst = append(dst, s...)
+	}
+	return dst
+}
+
+// Render returns the template rendered with v.
+func (t *Template) Render(v *Values) string {
+	var builder strings.Builder
+	builder.Grow(t.Len(v))
+	for i := 0; i < len(t.nodes); {
+		var s string
+		s, i = t.step(i, v)
+		builder.WriteString(s)
+	}
+	return builder.String()
+}
+
+// WriteBuffer writes the template rendered with v to buf.
+func (t *Template) WriteBuffer(buf *bytes.Buffer, v *Values) {
+	buf.Grow(t.Len(v))
+	for i := 0; i < len(t.nodes); {
+		var s string
+		s, i = t.step(i, v)
+		buf.WriteString(s)
+	}
+}
+
+// step executes node i and returns the text it produces together with the
+// index of the next node to execute.
+func (t *Template) step(i int, v *Values) (string, int) {
+	nd := &t.nodes[i]
+	switch nd.kind {
+	case nodeText:
+		return nd.text, i + 1
+	case nodeField:
+		return v.get(nd.field), i + 1
+	case nodeIf:
+		if v.get(nd.field) == "" {
+			return "", nd.skip
+		}
+	case nodeIfNot:
+		if v.get(nd.field) != "" {
+			return "", nd.skip
+		}
+	}
+	return "", i + 1
+}
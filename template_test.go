@@ -0,0 +1,163 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// TestTemplateRender tests rendering of placeholders and sections
+func TestTemplateRender(t *testing.T) {
+	full := Values{Name: "Müller", Title: "Dr.", TimeOfDay: "Good day", Punct: ", "}
+	bare := Values{Name: "Alice", Punct: ", "}
+
+	tests := []struct {
+		name     string
+		src      string
+		values   Values
+		expected string
+	}{
+		{"literal only", "Hello", bare, "Hello"},
+		{"name", "Hi, {name}", bare, "Hi, Alice"},
+		{"all fields", "{timeofday}{punct}{title} {name}", full, "Good day, Dr. Müller"},
+		{"section present", "Hi, {#title}{title} {/title}{name}", full, "Hi, Dr. Müller"},
+		{"section absent", "Hi, {#title}{title} {/title}{name}", bare, "Hi, Alice"},
+		{"inverted section", "{^timeofday}Hi{/timeofday}{timeofday}{punct}{name}", bare, "Hi, Alice"},
+		{"inverted section skipped", "{^timeofday}Hi{/timeofday}{timeofday}{punct}{name}", full, "Good day, Müller"},
+		{"nested sections", "{#name}{#title}{title} {/title}{name}{/name}", full, "Dr. Müller"},
+		{"escaped braces", "{{{name}}}", bare, "{Alice}"},
+		{"unicode literal", "{name}さん、こんにちは", bare, "Aliceさん、こんにちは"},
+		{"empty template", "", bare, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseTemplate(tt.src)
+			if err != nil {
+				t.Fatalf("ParseTemplate(%q) error: %v", tt.src, err)
+			}
+			if result := tmpl.Render(&tt.values); result != tt.expected {
+				t.Errorf("Render = %q, want %q", result, tt.expected)
+			}
+			if result := string(tmpl.Append(nil, &tt.values)); result != tt.expected {
+				t.Errorf("Append = %q, want %q", result, tt.expected)
+			}
+			var buf bytes.Buffer
+			tmpl.WriteBuffer(&buf, &tt.values)
+			if buf.String() != tt.expected {
+				t.Errorf("WriteBuffer = %q, want %q", buf.String(), tt.expected)
+			}
+			if n := tmpl.Len(&tt.values); n != len(tt.expected) {
+				t.Errorf("Len = %d, want %d", n, len(tt.expected))
+			}
+		})
+	}
+}
+
+// TestTemplateParseErrors tests error messages and positions for invalid templates
+func TestTemplateParseErrors(t *testing.T) {
+	tests := []struct {
+		src      string
+		line     int
+		column   int
+		expected string
+	}{
+		{"Hi, {nmae}", 1, 5, `template:1:5: unknown placeholder "nmae"`},
+		{"Hi, {name", 1, 5, "template:1:5: unclosed placeholder"},
+		{"Hi, {}", 1, 5, "template:1:5: empty placeholder"},
+		{"Hi} {name}", 1, 3, `template:1:3: unexpected "}"; use "}}" for a literal brace`},
+		{"{#title}{title}", 1, 1, "template:1:1: section {#title} is never closed"},
+		{"{name}{/title}", 1, 7, "template:1:7: {/title} without matching section"},
+		{"{#title}{#name}{/title}{/name}", 1, 16, "template:1:16: {/title} closes section {#name}"},
+		{"こんにちは\n{nome}", 2, 1, `template:2:1: unknown placeholder "nome"`},
+		{"こんにちは、{nome}", 1, 7, `template:1:7: unknown placeholder "nome"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := ParseTemplate(tt.src)
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("ParseTemplate(%q) error = %v, want *ParseError", tt.src, err)
+			}
+			if perr.Line != tt.line || perr.Column != tt.column {
+				t.Errorf("position = %d:%d, want %d:%d", perr.Line, perr.Column, tt.line, tt.column)
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Error() = %q, want %q", err.Error(), tt.expected)
+			}
+		})
+	}
+}
+
+// TestTemplateAllocs tests that rendering allocates nothing beyond the output
+func TestTemplateAllocs(t *testing.T) {
+	tmpl := MustParseTemplate("{timeofday}{punct}{#title}{title} {/title}{name}!")
+	v := Values{Name: "Müller", Title: "Dr.", TimeOfDay: "Good day", Punct: ", "}
+	buf := make([]byte, 0, 64)
+
+	if n := testing.AllocsPerRun(100, func() { tmpl.Append(buf[:0], &v) }); n != 0 {
+		t.Errorf("Append allocs = %v, want 0", n)
+	}
+	if n := testing.AllocsPerRun(100, func() { tmpl.Render(&v) }); n != 1 {
+		t.Errorf("Render allocs = %v, want 1", n)
+	}
+}
+
+// TestGreeterWithTemplate tests custom templates combined with catalog punctuation
+func TestGreeterWithTemplate(t *testing.T) {
+	tmpl := MustParseTemplate("Welcome back{punct}{name}")
+
+	tests := []struct {
+		tag      string
+		expected string
+	}{
+		{"en", "Welcome back, Alice"},
+		{"ja", "Welcome back、Alice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			g, err := NewGreeter(tt.tag, WithTemplate(tmpl))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result := g.SayHi("Alice"); result != tt.expected {
+				t.Errorf("SayHi = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+// BenchmarkTemplateRender benchmarks rendering a compiled template to a string
+func BenchmarkTemplateRender(b *testing.B) {
+	tmpl := MustParseTemplate("{timeofday}{punct}{#title}{title} {/title}{name}!")
+	v := Values{Name: "Benchmark", Title: "Dr.", TimeOfDay: "Good day", Punct: ", "}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tmpl.Render(&v)
+	}
+}
+
+// BenchmarkTemplateAppend benchmarks rendering into a reused buffer
+func BenchmarkTemplateAppend(b *testing.B) {
+	tmpl := MustParseTemplate("{timeofday}{punct}{#title}{title} {/title}{name}!")
+	v := Values{Name: "Benchmark", Title: "Dr.", TimeOfDay: "Good day", Punct: ", "}
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = tmpl.Append(buf[:0], &v)
+	}
+}
+
+// BenchmarkGreeterSayHi benchmarks a localized greeting
+func BenchmarkGreeterSayHi(b *testing.B) {
+	g, err := NewGreeter("pt-BR")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		g.SayHi("Benchmark")
+	}
+}
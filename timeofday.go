@@ -0,0 +1,205 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Clock tells the current time. Time-of-day greeters read it on every
+// greeting; tests can substitute a fixed clock.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts an ordinary function to the Clock interface.
+type ClockFunc func() time.Time
+
+// Now returns f().
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the Clock backed by time.Now.
+var SystemClock Clock = ClockFunc(time.Now)
+
+// DayPeriod is a salutation bucket of the day.
+type DayPeriod int
+
+// Day periods in the order they start after midnight. Night wraps around
+// midnight until the next morning.
+const (
+	Morning DayPeriod = iota
+	Afternoon
+	Evening
+	Night
+)
+
+var dayPeriodNames = [...]string{"morning", "afternoon", "evening", "night"}
+
+// String returns the period's name, which is also the catalog key holding
+// its salutation, e.g. "morning" → "Good morning".
+func (p DayPeriod) String() string {
+	if p < Morning || p > Night {
+		return fmt.Sprintf("DayPeriod(%d)", int(p))
+	}
+	return dayPeriodNames[p]
+}
+
+// DayPeriods holds the wall-clock start of each salutation bucket as an
+// offset from midnight. Starts must be strictly increasing, from Morning to
+// Night, and lie within a single day.
+type DayPeriods struct {
+	Morning   time.Duration
+	Afternoon time.Duration
+	Evening   time.Duration
+	Night     time.Duration
+}
+
+// DefaultDayPeriods are the boundaries used for locales whose catalog does
+// not define its own: morning at 05:00, afternoon at 12:00, evening at 18:00
+// and night at 22:00.
+var DefaultDayPeriods = DayPeriods{
+	Morning:   5 * time.Hour,
+	Afternoon: 12 * time.Hour,
+	Evening:   18 * time.Hour,
+	Night:     22 * time.Hour,
+}
+
+// Validate reports whether the boundaries are in order and within a day.
+func (d DayPeriods) Validate() error {
+	starts := [...]time.Duration{d.Morning, d.Afternoon, d.Evening, d.Night}
+	if starts[0] < 0 || starts[3] >= 24*time.Hour {
+		return fmt.Errorf("day periods %v: boundaries must lie within a day", d)
+	}
+	for i := 1; i < len(starts); i++ {
+		if starts[i] <= starts[i-1] {
+			return fmt.Errorf("day periods %v: %s must start after %s", d, DayPeriod(i), DayPeriod(i-1))
+		}
+	}
+	return nil
+}
+
+// Period returns the bucket t falls into, judged by t's wall clock in its
+// own location.
+func (d DayPeriods) Period(t time.Time) DayPeriod {
+	hour, min, sec := t.Clock()
+	since := time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute + time.Duration(sec)*time.Second
+	switch {
+	case since < d.Morning || since >= d.Night:
+		return Night
+	case since >= d.Evening:
+		return Evening
+	case since >= d.Afternoon:
+		return Afternoon
+	default:
+		return Morning
+	}
+}
+
+// String formats the boundaries the way catalogs spell them,
+// e.g. "05:00 12:00 18:00 22:00".
+func (d DayPeriods) String() string {
+	starts := [...]time.Duration{d.Morning, d.Afternoon, d.Evening, d.Night}
+	parts := make([]string, len(starts))
+	for i, s := range starts {
+		parts[i] = fmt.Sprintf("%02d:%02d", int(s/time.Hour), int(s%time.Hour/time.Minute))
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseDayPeriods parses four space-separated HH:MM boundaries, in the
+// order morning, afternoon, evening, night.
+func ParseDayPeriods(s string) (DayPeriods, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 4 {
+		return DayPeriods{}, fmt.Errorf("day periods %q: want 4 boundaries, got %d", s, len(fields))
+	}
+
+	var starts [4]time.Duration
+	for i, f := range fields {
+		var hour, min int
+		if n, err := fmt.Sscanf(f, "%d:%d", &hour, &min); n != 2 || err != nil || hour < 0 || min < 0 || min > 59 {
+			return DayPeriods{}, fmt.Errorf("day periods %q: bad boundary %q, want HH:MM", s, f)
+		}
+		starts[i] = time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute
+	}
+
+	d := DayPeriods{Morning: starts[0], Afternoon: starts[1], Evening: starts[2], Night: starts[3]}
+	if err := d.Validate(); err != nil {
+		return DayPeriods{}, err
+	}
+	return d, nil
+}
+
+var (
+	defaultGreeterOnce     sync.Once
+	defaultGreeterInstance *LocaleGreeter
+)
+
+func defaultGreeter() *LocaleGreeter {
+	defaultGreeterOnce.Do(func() {
+		g, err := NewGreeter(DefaultLocale)
+		if err != nil {
+			panic("test: building default greeter: " + err.Error())
+		}
+		defaultGreeterInstance = g
+	})
+	return defaultGreeterInstance
+}
+
+// SayHiAt generates a greeting that fits the time of day at t, e.g.
+// "Good morning, Alice". The period is judged by t's wall clock, so convert
+// t with In to greet in another timezone.
+//
+// Example:
+//
+//	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+//	message := SayHiAt("Alice", time.Now().In(tokyo))
+//
+// Thread Safety:
+//   This function is safe for concurrent use by multiple goroutines.
+func SayHiAt(name string, t time.Time) string {
+	return defaultGreeter().SayHiAt(name, t)
+}
+
+// SayHiAtBytes returns the time-of-day greeting as a byte slice.
+func SayHiAtBytes(name string, t time.Time) []byte {
+	return defaultGreeter().SayHiAtBytes(name, t)
+}
+
+// SayHiAtBuffer writes the time-of-day greeting to a bytes.Buffer.
+func SayHiAtBuffer(name string, t time.Time, buf *bytes.Buffer) {
+	defaultGreeter().SayHiAtBuffer(name, t, buf)
+}
+
+// SayHiAt generates a localized greeting that fits the time of day at t.
+// If the LocaleGreeter has a location, t is converted to it first.
+func (g *LocaleGreeter) SayHiAt(name string, t time.Time) string {
+	v := Values{Name: name, Punct: g.punct}
+	g.setTimeOfDay(&v, t)
+	return g.timeTemplate.Render(&v)
+}
+
+// SayHiAtBytes returns the localized time-of-day greeting as a byte slice.
+func (g *LocaleGreeter) SayHiAtBytes(name string, t time.Time) []byte {
+	v := Values{Name: name, Punct: g.punct}
+	g.setTimeOfDay(&v, t)
+	return g.timeTemplate.Append(make([]byte, 0, g.timeTemplate.Len(&v)), &v)
+}
+
+// SayHiAtBuffer writes the localized time-of-day greeting to a bytes.Buffer.
+func (g *LocaleGreeter) SayHiAtBuffer(name string, t time.Time, buf *bytes.Buffer) {
+	v := Values{Name: name, Punct: g.punct}
+	g.setTimeOfDay(&v, t)
+	g.timeTemplate.WriteBuffer(buf, &v)
+}
+
+func (g *LocaleGreeter) setTimeOfDay(v *Values, t time.Time) {
+	if g.location != nil {
+		t = t.In(g.location)
+	}
+	v.TimeOfDay = g.salutations[g.periods.Period(t)]
+}
@@ -0,0 +1,149 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func at(hour, min int) time.Time {
+	return time.Date(2024, time.March, 1, hour, min, 0, 0, time.UTC)
+}
+
+// TestDayPeriodsPeriod tests bucket selection around the boundaries
+func TestDayPeriodsPeriod(t *testing.T) {
+	tests := []struct {
+		time     time.Time
+		expected DayPeriod
+	}{
+		{at(0, 0), Night},
+		{at(4, 59), Night},
+		{at(5, 0), Morning},
+		{at(11, 59), Morning},
+		{at(12, 0), Afternoon},
+		{at(18, 0), Evening},
+		{at(21, 59), Evening},
+		{at(22, 0), Night},
+		{at(23, 59), Night},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.time.Format("15:04"), func(t *testing.T) {
+			if result := DefaultDayPeriods.Period(tt.time); result != tt.expected {
+				t.Errorf("Period(%s) = %v, want %v", tt.time.Format("15:04"), result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestParseDayPeriods tests parsing and validation of boundary strings
+func TestParseDayPeriods(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"05:00 12:00 18:00 22:00", false},
+		{"06:30 13:00 21:00 23:45", false},
+		{"05:00 12:00 18:00", true},
+		{"05:00 12:00 11:00 22:00", true},
+		{"05:00 12:00 18:00 24:00", true},
+		{"5h 12:00 18:00 22:00", true},
+		{"05:75 12:00 18:00 22:00", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			d, err := ParseDayPeriods(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDayPeriods(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if err == nil && d.String() != tt.input {
+				t.Errorf("String() = %q, want %q", d.String(), tt.input)
+			}
+		})
+	}
+}
+
+// TestSayHiAt tests the package-level time-of-day greetings
+func TestSayHiAt(t *testing.T) {
+	tests := []struct {
+		time     time.Time
+		expected string
+	}{
+		{at(8, 0), "Good morning, Alice"},
+		{at(14, 0), "Good afternoon, Alice"},
+		{at(19, 0), "Good evening, Alice"},
+		{at(2, 0), "Good evening, Alice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if result := SayHiAt("Alice", tt.time); result != tt.expected {
+				t.Errorf("SayHiAt = %q, want %q", result, tt.expected)
+			}
+			if result := string(SayHiAtBytes("Alice", tt.time)); result != tt.expected {
+				t.Errorf("SayHiAtBytes = %q, want %q", result, tt.expected)
+			}
+			var buf bytes.Buffer
+			SayHiAtBuffer("Alice", tt.time, &buf)
+			if buf.String() != tt.expected {
+				t.Errorf("SayHiAtBuffer = %q, want %q", buf.String(), tt.expected)
+			}
+		})
+	}
+}
+
+// TestGreeterTimeOfDay tests locale salutations, timezones and clocks
+func TestGreeterTimeOfDay(t *testing.T) {
+	clock := ClockFunc(func() time.Time { return at(12, 30) })
+
+	tests := []struct {
+		name     string
+		tag      string
+		opts     []Option
+		expected string
+	}{
+		{"english clock", "en", nil, "Good afternoon, Alice"},
+		{"japanese", "ja", nil, "こんにちは、Alice"},
+		{"brazilian falls back to pt", "pt-BR", nil, "Boa tarde, Alice"},
+		{"spanish locale boundaries", "es", nil, "Buenos días, Alice"},
+		{"timezone", "en", []Option{WithTimeZone("Asia/Tokyo")}, "Good evening, Alice"},
+		{"custom periods", "en", []Option{WithDayPeriods(DayPeriods{
+			Morning: 6 * time.Hour, Afternoon: 13 * time.Hour, Evening: 17 * time.Hour, Night: 23 * time.Hour,
+		})}, "Good morning, Alice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGreeter(tt.tag, append(tt.opts, WithTimeOfDay(clock))...)
+			if err != nil {
+				t.Fatalf("NewGreeter error: %v", err)
+			}
+			if result := g.SayHi("Alice"); result != tt.expected {
+				t.Errorf("SayHi = %q, want %q", result, tt.expected)
+			}
+			if result := string(g.SayHiBytes("Alice")); result != tt.expected {
+				t.Errorf("SayHiBytes = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestGreeterTimeOfDayErrors tests invalid time-of-day settings
+func TestGreeterTimeOfDayErrors(t *testing.T) {
+	if _, err := NewGreeter("en", WithTimeZone("Mars/Olympus_Mons")); err == nil {
+		t.Error("NewGreeter accepted an unknown timezone")
+	}
+	if _, err := NewGreeter("en", WithDayPeriods(DayPeriods{Morning: time.Hour})); err == nil {
+		t.Error("NewGreeter accepted unordered day periods")
+	}
+}
+
+// BenchmarkSayHiAt benchmarks time-of-day greetings
+func BenchmarkSayHiAt(b *testing.B) {
+	now := at(9, 0)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		SayHiAt("Benchmark", now)
+	}
+}
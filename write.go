@@ -0,0 +1,57 @@
+package test
+
+import "io"
+
+// AppendGreeting appends the greeting for name to dst and returns the
+// extended buffer, in the style of strconv.AppendInt. It does not allocate
+// when dst has enough spare capacity.
+//
+// Example:
+//
+//	buf := make([]byte, 0, 64)
+//	buf = AppendGreeting(buf, "Alice")
+//	buf = append(buf, '\n')
+func AppendGreeting(dst []byte, name string) []byte {
+	dst = append(dst, greetingPrefix...)
+	return append(dst, name...)
+}
+
+// WriteGreeting writes the greeting for name to w in a single Write call
+// and returns the number of bytes written. Any error from w is returned
+// unchanged; a short write without an error is reported as
+// io.ErrShortWrite. The greeting is rendered with Render, so
+// WriteGreeting does not allocate in the steady state.
+//
+// Example:
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		test.WriteGreeting(w, r.URL.Query().Get("name"))
+//	}
+//
+// Thread Safety:
+//   This function is safe for concurrent use by multiple goroutines, as long
+//   as concurrent calls do not share a w that is itself unsafe for it.
+func WriteGreeting(w io.Writer, name string) (int, error) {
+	return writeRendered(w, Render(name))
+}
+
+// AppendGreeting appends the localized greeting for name to dst and
+// returns the extended buffer.
+func (g *LocaleGreeter) AppendGreeting(dst []byte, name string) []byte {
+	v := Values{Name: name}
+	t := g.prepare(&v)
+	return t.Append(dst, &v)
+}
+
+// WriteGreeting writes the localized greeting for name to w in a single
+// Write call. See the package-level WriteGreeting.
+func (g *LocaleGreeter) WriteGreeting(w io.Writer, name string) (int, error) {
+	return writeRendered(w, g.Render(name))
+}
+
+// writeRendered writes r to w and releases it.
+func writeRendered(w io.Writer, r *Rendered) (int, error) {
+	n, err := r.WriteTo(w)
+	r.Release()
+	return int(n), err
+}
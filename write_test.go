@@ -0,0 +1,130 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// failingWriter fails every write after accepting limit bytes
+type failingWriter struct {
+	limit int
+	err   error
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) <= w.limit {
+		return len(p), nil
+	}
+	return w.limit, w.err
+}
+
+// TestAppendGreeting tests appending to existing buffers
+func TestAppendGreeting(t *testing.T) {
+	tests := []struct {
+		dst      string
+		name     string
+		expected string
+	}{
+		{"", "Alice", "Hi, Alice"},
+		{"", "", "Hi, "},
+		{"> ", "José", "> Hi, José"},
+	}
+
+	for _, tt := range tests {
+		if result := string(AppendGreeting([]byte(tt.dst), tt.name)); result != tt.expected {
+			t.Errorf("AppendGreeting(%q, %q) = %q, want %q", tt.dst, tt.name, result, tt.expected)
+		}
+	}
+}
+
+// TestWriteGreeting tests writing greetings and propagating writer errors
+func TestWriteGreeting(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := WriteGreeting(&buf, "Alice")
+	if err != nil || n != len("Hi, Alice") || buf.String() != "Hi, Alice" {
+		t.Errorf("WriteGreeting = %d, %v, wrote %q", n, err, buf.String())
+	}
+
+	errBroken := errors.New("broken pipe")
+	n, err = WriteGreeting(&failingWriter{limit: 3, err: errBroken}, "Alice")
+	if !errors.Is(err, errBroken) || n != 3 {
+		t.Errorf("WriteGreeting to failing writer = %d, %v, want 3, %v", n, err, errBroken)
+	}
+
+	n, err = WriteGreeting(&failingWriter{limit: 3}, "Alice")
+	if err != io.ErrShortWrite || n != 3 {
+		t.Errorf("WriteGreeting to short writer = %d, %v, want 3, %v", n, err, io.ErrShortWrite)
+	}
+
+	long := strings.Repeat("x", 2*renderSizeClasses[len(renderSizeClasses)-1])
+	buf.Reset()
+	if _, err := WriteGreeting(&buf, long); err != nil || buf.String() != SayHi(long) {
+		t.Errorf("WriteGreeting with long name = %v", err)
+	}
+}
+
+// TestGreeterWriteGreeting tests the localized writer API
+func TestGreeterWriteGreeting(t *testing.T) {
+	g, err := NewGreeter("pt-BR")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := string(g.AppendGreeting([]byte("> "), "Alice")); result != "> Oi, Alice" {
+		t.Errorf("AppendGreeting = %q, want %q", result, "> Oi, Alice")
+	}
+
+	var buf bytes.Buffer
+	if _, err := g.WriteGreeting(&buf, "Alice"); err != nil || buf.String() != "Oi, Alice" {
+		t.Errorf("WriteGreeting = %v, wrote %q", err, buf.String())
+	}
+}
+
+// TestWriteGreetingAllocs tests the zero-allocation guarantees
+func TestWriteGreetingAllocs(t *testing.T) {
+	buf := make([]byte, 0, 64)
+	if n := testing.AllocsPerRun(100, func() { AppendGreeting(buf[:0], "Alice") }); n != 0 {
+		t.Errorf("AppendGreeting allocs = %v, want 0", n)
+	}
+	if n := testing.AllocsPerRun(100, func() { WriteGreeting(io.Discard, "Alice") }); n != 0 {
+		t.Errorf("WriteGreeting allocs = %v, want 0", n)
+	}
+}
+
+// BenchmarkSayHiBytes benchmarks the allocating byte API for comparison
+func BenchmarkSayHiBytes(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		io.Discard.Write(SayHiBytes("Benchmark"))
+	}
+}
+
+// BenchmarkAppendGreeting benchmarks appending into a reused buffer
+func BenchmarkAppendGreeting(b *testing.B) {
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = AppendGreeting(buf[:0], "Benchmark")
+	}
+}
+
+// BenchmarkWriteGreeting benchmarks writing through the pooled buffer
+func BenchmarkWriteGreeting(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		WriteGreeting(io.Discard, "Benchmark")
+	}
+}
+
+// BenchmarkWriteGreetingParallel benchmarks the pooled buffer under contention
+func BenchmarkWriteGreetingParallel(b *testing.B) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			WriteGreeting(io.Discard, "Benchmark")
+		}
+	})
+}